@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	pb "grpc-demo/proto" // 引入自定义的proto包
+)
+
+// runHello 调用 SayHello 发送一次问候
+func runHello(ctx context.Context, client pb.GreeterClient, w io.Writer, name string) error {
+	res, err := client.SayHello(ctx, &pb.HelloRequest{Name: name})
+	if err != nil {
+		return fmt.Errorf("请求失败: %w", err)
+	}
+	fmt.Fprintf(w, "收到响应: %s\n", res.GetMessage())
+	return nil
+}
+
+// runStream 调用 SayHelloStream，逐条输出服务端推送的问候
+func runStream(ctx context.Context, client pb.GreeterClient, w io.Writer, name string, count int, interval time.Duration) error {
+	stream, err := client.SayHelloStream(ctx, &pb.HelloStreamRequest{
+		Name:     name,
+		Count:    int32(count),
+		Interval: durationpb.New(interval),
+	})
+	if err != nil {
+		return fmt.Errorf("请求失败: %w", err)
+	}
+	for {
+		res, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("接收失败: %w", err)
+		}
+		fmt.Fprintf(w, "收到响应: %s\n", res.GetMessage())
+	}
+}
+
+// runMany 调用 SayHelloToMany，依次发送全部名字后输出汇总的问候
+func runMany(ctx context.Context, client pb.GreeterClient, w io.Writer, names []string) error {
+	stream, err := client.SayHelloToMany(ctx)
+	if err != nil {
+		return fmt.Errorf("请求失败: %w", err)
+	}
+	for _, name := range names {
+		if err := stream.Send(&pb.HelloRequest{Name: name}); err != nil {
+			// 服务端提前结束时 Send 返回 io.EOF，真正的错误由 CloseAndRecv 返回
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("发送失败: %w", err)
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return fmt.Errorf("请求失败: %w", err)
+	}
+	fmt.Fprintf(w, "收到响应: %s (共 %d 人)\n", res.GetMessage(), len(res.GetNames()))
+	return nil
+}
+
+// runChat 调用 Chat，把 r 中的每一行作为一条消息发送，同时输出服务端的回复
+// r 读取结束后关闭发送，等待服务端回复完毕再返回
+func runChat(ctx context.Context, client pb.GreeterClient, r io.Reader, w io.Writer, name string) error {
+	stream, err := client.Chat(ctx)
+	if err != nil {
+		return fmt.Errorf("请求失败: %w", err)
+	}
+
+	// 接收和发送在不同的 goroutine 中进行，服务端的回复不需要等待下一行输入
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				recvErr <- nil
+				return
+			}
+			if err != nil {
+				recvErr <- fmt.Errorf("接收失败: %w", err)
+				return
+			}
+			fmt.Fprintf(w, "%s: %s\n", msg.GetFrom(), msg.GetText())
+		}
+	}()
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		if sc.Text() == "" {
+			continue
+		}
+		if err := stream.Send(&pb.ChatMessage{From: name, Text: sc.Text()}); err != nil {
+			// 服务端已经结束，错误由接收方返回
+			break
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("读取输入失败: %w", err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		return fmt.Errorf("关闭发送失败: %w", err)
+	}
+	return <-recvErr
+}
+
+// printError 输出调用失败的原因：gRPC 错误输出状态码和描述，并逐条列出 BadRequest 中不合法的字段
+func printError(w io.Writer, err error) {
+	// 命令返回的错误包装了 gRPC 错误，status.FromError 会把包装后的整段文字作为描述，这里取出原始的状态
+	var se interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &se) {
+		fmt.Fprintln(w, err)
+		return
+	}
+	st := se.GRPCStatus()
+	fmt.Fprintf(w, "请求失败 [%s]: %s\n", st.Code(), st.Message())
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				fmt.Fprintf(w, "  - %s: %s\n", v.GetField(), v.GetDescription())
+			}
+		}
+	}
+}
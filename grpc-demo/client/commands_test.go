@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	pb "grpc-demo/proto"
+)
+
+// fakeGreeter 回显请求内容的 Greeter 服务，用于检查客户端发送和输出的内容
+type fakeGreeter struct {
+	pb.UnimplementedGreeterServer
+}
+
+func (fakeGreeter) SayHello(_ context.Context, req *pb.HelloRequest) (*pb.HelloReply, error) {
+	return &pb.HelloReply{Message: "hello " + req.GetName()}, nil
+}
+
+func (fakeGreeter) SayHelloStream(req *pb.HelloStreamRequest, stream grpc.ServerStreamingServer[pb.HelloReply]) error {
+	for range req.GetCount() {
+		if err := stream.Send(&pb.HelloReply{Message: req.GetName() + " " + req.GetInterval().AsDuration().String()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fakeGreeter) SayHelloToMany(stream grpc.ClientStreamingServer[pb.HelloRequest, pb.ManyHelloReply]) error {
+	var names []string
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&pb.ManyHelloReply{Message: strings.Join(names, "+"), Names: names})
+		}
+		if err != nil {
+			return err
+		}
+		names = append(names, req.GetName())
+	}
+}
+
+func (fakeGreeter) Chat(stream grpc.BidiStreamingServer[pb.ChatMessage, pb.ChatMessage]) error {
+	for {
+		in, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&pb.ChatMessage{From: "echo", Text: in.GetFrom() + ":" + in.GetText()}); err != nil {
+			return err
+		}
+	}
+}
+
+func newTestClient(t *testing.T) pb.GreeterClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	pb.RegisterGreeterServer(s, fakeGreeter{})
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewGreeterClient(conn)
+}
+
+func TestCommands(t *testing.T) {
+	client := newTestClient(t)
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, w io.Writer) error
+		want string
+	}{
+		{"hello", func(ctx context.Context, w io.Writer) error {
+			return runHello(ctx, client, w, "张三")
+		}, "收到响应: hello 张三\n"},
+		{"stream", func(ctx context.Context, w io.Writer) error {
+			return runStream(ctx, client, w, "张三", 2, time.Second)
+		}, "收到响应: 张三 1s\n收到响应: 张三 1s\n"},
+		{"many", func(ctx context.Context, w io.Writer) error {
+			return runMany(ctx, client, w, []string{"a", "b", "c"})
+		}, "收到响应: a+b+c (共 3 人)\n"},
+		{"chat", func(ctx context.Context, w io.Writer) error {
+			return runChat(ctx, client, strings.NewReader("在吗\n\n再见\n"), w, "张三")
+		}, "echo: 张三:在吗\necho: 张三:再见\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if err := tt.run(t.Context(), &out); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRunStreamRejectsBadFlags(t *testing.T) {
+	client := newTestClient(t)
+	// -n 0 时服务端按默认次数发送，客户端的超时时间会短于实际需要的时间
+	for _, args := range [][]string{{"stream", "-n", "0"}, {"stream", "-n", "-1"}, {"stream", "-interval", "-1s"}} {
+		if err := run(t.Context(), client, args); err == nil {
+			t.Errorf("run(%q): want error", args)
+		}
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	st, err := status.New(codes.InvalidArgument, "请求参数不合法").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "from", Description: "不能为空"},
+			{Field: "text", Description: "长度不能超过 1024 个字符"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	printError(&out, fmt.Errorf("请求失败: %w", st.Err()))
+	want := "请求失败 [InvalidArgument]: 请求参数不合法\n  - from: 不能为空\n  - text: 长度不能超过 1024 个字符\n"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	printError(&out, errors.New("many 需要至少一个名字"))
+	if out.String() != "many 需要至少一个名字\n" {
+		t.Errorf("非 gRPC 错误: got %q", out.String())
+	}
+}
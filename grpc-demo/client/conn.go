@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"log/slog"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"grpc-demo/discovery"
+)
+
+// defaultServiceConfig 内置的 service config：
+//   - round_robin 负载均衡，跳过健康检查不是 SERVING 的后端
+//   - SayHello 超时 1s、SayHelloToMany 超时 5s；流式调用的时长由调用方决定，不设置超时
+//   - 全部方法 waitForReady：连接尚未就绪或暂时断开时等待，而不是立即返回 UNAVAILABLE
+//   - 除 Chat 外在 UNAVAILABLE 时按指数退避重试；Chat 已经收发的消息无法重放，不重试
+//
+//go:embed service_config.json
+var defaultServiceConfig string
+
+// connConfig 客户端连接的配置
+type connConfig struct {
+	// ServiceConfig JSON 格式的 service config，为空时使用 defaultServiceConfig
+	ServiceConfig string
+	// Keepalive 空闲时发送 ping 的间隔和等待回应的超时，服务端的 EnforcementPolicy 需要允许这个间隔
+	Keepalive keepalive.ClientParameters
+	// DialOptions 拦截器等额外的选项
+	DialOptions []grpc.DialOption
+}
+
+// loadServiceConfig 读取 path 中的 service config，path 为空时返回内置的配置
+func loadServiceConfig(path string) (string, error) {
+	if path == "" {
+		return defaultServiceConfig, nil
+	}
+	data, err := os.ReadFile(path)
+	return string(data), err
+}
+
+// newConn 使用 grpc.NewClient 创建到 target 的连接，target 的格式见 discovery.Target。
+// 连接在第一次调用时才建立，service config 不合法时直接返回错误
+func newConn(target string, cfg connConfig) (*grpc.ClientConn, error) {
+	serviceConfig := cfg.ServiceConfig
+	if serviceConfig == "" {
+		serviceConfig = defaultServiceConfig
+	}
+	opts := append([]grpc.DialOption{
+		// 使用insecure连接到gRPC服务器，对于生产环境，应该使用安全的连接
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		discovery.Resolvers(),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		grpc.WithKeepaliveParams(cfg.Keepalive),
+	}, cfg.DialOptions...)
+	return grpc.NewClient(discovery.Target(target), opts...)
+}
+
+// logStateChanges 记录连接状态的每次变化，直到 ctx 结束
+func logStateChanges(ctx context.Context, conn *grpc.ClientConn, logger *slog.Logger) {
+	state := conn.GetState()
+	since := time.Now()
+	logger.Info("连接状态", "target", conn.CanonicalTarget(), "state", state)
+	for conn.WaitForStateChange(ctx, state) {
+		prev := state
+		state = conn.GetState()
+		level := slog.LevelInfo
+		if state == connectivity.TransientFailure {
+			level = slog.LevelWarn
+		}
+		logger.Log(ctx, level, "连接状态", "target", conn.CanonicalTarget(), "state", state, "from", prev, "after", time.Since(since))
+		since = time.Now()
+	}
+}
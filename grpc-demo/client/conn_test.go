@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	pb "grpc-demo/proto"
+)
+
+// flakyGreeter 前 failures 次调用返回 code，之后正常回复；block 为 true 时一直等到调用超时
+type flakyGreeter struct {
+	pb.UnimplementedGreeterServer
+	failures int32
+	code     codes.Code
+	block    bool
+	calls    atomic.Int32
+}
+
+func (g *flakyGreeter) SayHello(ctx context.Context, req *pb.HelloRequest) (*pb.HelloReply, error) {
+	if g.calls.Add(1) <= g.failures {
+		return nil, status.Error(g.code, "暂时不可用")
+	}
+	if g.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &pb.HelloReply{Message: "hello " + req.GetName()}, nil
+}
+
+// newBufconnConn 用 newConn 连接到内存中的 g；ready 为 nil 时直接可连，
+// 否则在 ready 关闭之前拨号一律失败，模拟服务端尚未启动
+func newBufconnConn(t *testing.T, g pb.GreeterServer, serviceConfig string, ready <-chan struct{}) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	pb.RegisterGreeterServer(s, g)
+	// 内置配置对 greet.Greeter 做健康检查
+	hs := health.NewServer()
+	hs.SetServingStatus(pb.Greeter_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, hs)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		if ready != nil {
+			select {
+			case <-ready:
+			default:
+				return nil, errors.New("服务端尚未启动")
+			}
+		}
+		return lis.DialContext(ctx)
+	}
+	conn, err := newConn("passthrough:///bufnet", connConfig{
+		ServiceConfig: serviceConfig,
+		DialOptions: []grpc.DialOption{
+			grpc.WithContextDialer(dialer),
+			// 缩短重连间隔，测试不必等待默认的 1 秒
+			grpc.WithConnectParams(grpc.ConnectParams{Backoff: backoff.Config{BaseDelay: 10 * time.Millisecond, Multiplier: 1, MaxDelay: 10 * time.Millisecond}}),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestDefaultServiceConfig(t *testing.T) {
+	var sc struct {
+		MethodConfig []struct {
+			Name []struct {
+				Service, Method string
+			}
+			Timeout      string
+			WaitForReady bool
+			RetryPolicy  *struct{ MaxAttempts int }
+		}
+	}
+	if err := json.Unmarshal([]byte(defaultServiceConfig), &sc); err != nil {
+		t.Fatal(err)
+	}
+	methods := make(map[string]string)
+	for _, mc := range sc.MethodConfig {
+		if !mc.WaitForReady {
+			t.Errorf("%v 没有设置 waitForReady", mc.Name)
+		}
+		for _, n := range mc.Name {
+			if n.Service != pb.Greeter_ServiceDesc.ServiceName {
+				t.Errorf("service = %q", n.Service)
+			}
+			methods[n.Method] = mc.Timeout
+			if n.Method == "Chat" && mc.RetryPolicy != nil {
+				t.Error("Chat 不应当重试")
+			}
+		}
+	}
+	if methods["SayHello"] != "1s" || methods["SayHelloToMany"] != "5s" || len(methods) != 4 {
+		t.Errorf("methods = %v", methods)
+	}
+
+	// grpc.NewClient 会校验 service config
+	if _, err := newConn("passthrough:///x", connConfig{ServiceConfig: `{"methodConfig": [{"timeout": 1}]}`}); err == nil {
+		t.Error("不合法的 service config 应当返回错误")
+	}
+	if _, err := loadServiceConfig("testdata/missing.json"); err == nil {
+		t.Error("读取不存在的文件应当返回错误")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	g := &flakyGreeter{failures: 2, code: codes.Unavailable}
+	client := pb.NewGreeterClient(newBufconnConn(t, g, "", nil))
+	res, err := client.SayHello(t.Context(), &pb.HelloRequest{Name: "张三"})
+	if err != nil || res.GetMessage() != "hello 张三" {
+		t.Fatalf("got %v, %v", res, err)
+	}
+	if got := g.calls.Load(); got != 3 {
+		t.Errorf("calls = %d, want 3 (两次 UNAVAILABLE 后重试成功)", got)
+	}
+
+	// 不在 retryableStatusCodes 中的错误不重试
+	g = &flakyGreeter{failures: 1, code: codes.InvalidArgument}
+	client = pb.NewGreeterClient(newBufconnConn(t, g, "", nil))
+	if _, err := client.SayHello(t.Context(), &pb.HelloRequest{Name: "张三"}); status.Code(err) != codes.InvalidArgument || g.calls.Load() != 1 {
+		t.Errorf("err = %v, calls = %d", err, g.calls.Load())
+	}
+}
+
+func TestMethodTimeout(t *testing.T) {
+	sc := `{"methodConfig": [{"name": [{"service": "greet.Greeter", "method": "SayHello"}], "timeout": "0.05s"}]}`
+	client := pb.NewGreeterClient(newBufconnConn(t, &flakyGreeter{block: true}, sc, nil))
+	start := time.Now()
+	_, err := client.SayHello(t.Context(), &pb.HelloRequest{Name: "张三"})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("err = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("service config 中的 50ms 超时没有生效，耗时 %v", elapsed)
+	}
+}
+
+func TestWaitForReady(t *testing.T) {
+	ready := make(chan struct{})
+	conn := newBufconnConn(t, &flakyGreeter{}, "", ready)
+	client := pb.NewGreeterClient(conn)
+
+	// 服务端启动前发起的调用一直等待，而不是立即返回 UNAVAILABLE
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.SayHello(t.Context(), &pb.HelloRequest{Name: "张三"})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("服务端启动前调用就返回了: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(ready)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有 waitForReady 的配置下，连接失败时立即返回 UNAVAILABLE
+	noWait := newBufconnConn(t, &flakyGreeter{}, `{}`, make(chan struct{}))
+	if _, err := pb.NewGreeterClient(noWait).SayHello(t.Context(), &pb.HelloRequest{Name: "张三"}); status.Code(err) != codes.Unavailable {
+		t.Errorf("err = %v, want Unavailable", err)
+	}
+}
+
+// lockedBuilder 可以在写入的同时读取的 strings.Builder
+type lockedBuilder struct {
+	mu sync.Mutex
+	b  strings.Builder
+}
+
+func (l *lockedBuilder) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.Write(p)
+}
+
+func (l *lockedBuilder) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.String()
+}
+
+func TestLogStateChanges(t *testing.T) {
+	conn := newBufconnConn(t, &flakyGreeter{}, "", nil)
+	var out lockedBuilder
+	logger := slog.New(slog.NewTextHandler(&out, nil))
+	ctx, cancel := context.WithCancel(t.Context())
+	stopped := make(chan struct{})
+	go func() {
+		logStateChanges(ctx, conn, logger)
+		close(stopped)
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	if _, err := pb.NewGreeterClient(conn).SayHello(t.Context(), &pb.HelloRequest{Name: "张三"}); err != nil {
+		t.Fatal(err)
+	}
+	// 日志协程开始时连接可能已经离开 IDLE，只检查最终的 READY
+	want := "state=READY from=CONNECTING"
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(out.String(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("日志中没有 %q:\n%s", want, out.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
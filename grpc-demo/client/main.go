@@ -2,19 +2,74 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
 	"time"
 
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"grpc-demo/interceptor"
 	pb "grpc-demo/proto" // 引入自定义的proto包
 )
 
+const usage = `用法: client [-target 地址] [-v] [-lang 语言] [-trace-exporter stdout|otlp|none] 子命令
+  client [hello] [名字]                          单次问候（默认）
+  client stream [-n 次数] [-interval 间隔] [名字]  服务端流式：重复接收问候
+  client many 名字...                            客户端流式：一次问候多个人
+  client chat [名字]                             双向流式：从标准输入逐行发送消息
+`
+
 // main 是程序的入口点
 func main() {
-	// 使用insecure连接到gRPC服务器，对于生产环境，应该使用安全的连接
-	conn, err := grpc.Dial("localhost:50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	target := flag.String("target", "localhost:50051", "服务端地址：单个地址、逗号分隔的多个地址、static:///a,b,c、file:///后端列表文件或 dns:///host:port")
+	verbose := flag.Bool("v", false, "记录每次 gRPC 调用的方法、状态码和耗时")
+	traceExporter := flag.String("trace-exporter", "none", "链路追踪导出方式: stdout、otlp、none")
+	otlpEndpoint := flag.String("otlp-endpoint", "localhost:4317", "trace-exporter 为 otlp 时 collector 的地址")
+	metricsAddr := flag.String("metrics-addr", "", "Prometheus /metrics 的 HTTP 监听地址，为空时只记录不提供")
+	serviceConfig := flag.String("service-config", "", "JSON 格式的 service config 文件，配置负载均衡、各方法的超时、重试和 waitForReady；为空时使用内置配置")
+	keepaliveTime := flag.Duration("keepalive-time", 30*time.Second, "连接空闲多久后发送 keepalive ping，最小 10s")
+	keepaliveTimeout := flag.Duration("keepalive-timeout", 10*time.Second, "等待 keepalive ping 回应的时间，超时后断开连接")
+	lang := flag.String("lang", "", "问候语的语言偏好，格式同 Accept-Language，如 en 或 zh-CN,en;q=0.8；为空时使用服务端的默认语言")
+	flag.Usage = func() { fmt.Fprint(flag.CommandLine.Output(), usage) }
+	flag.Parse()
+
+	tp, err := interceptor.NewTracerProvider(context.Background(), "greeter-client", *traceExporter, *otlpEndpoint)
+	if err != nil {
+		log.Fatalf("初始化链路追踪失败: %v", err)
+	}
+	// 退出前导出剩余的 span
+	defer tp.Shutdown(context.Background())
+	cfg := interceptor.Config{TracerProvider: tp, Metrics: interceptor.NewMetrics()}
+	if *metricsAddr != "" {
+		go func() {
+			log.Printf("指标服务运行中: http://%s/metrics", *metricsAddr)
+			if err := cfg.Metrics.ListenAndServe(*metricsAddr); err != nil {
+				log.Printf("指标服务失败: %v", err)
+			}
+		}()
+	}
+	if *verbose {
+		cfg.Logger = slog.New(slog.NewTextHandler(os.Stderr, nil))
+	}
+
+	sc, err := loadServiceConfig(*serviceConfig)
+	if err != nil {
+		log.Fatalf("读取 service config 失败: %v", err)
+	}
+	conn, err := newConn(*target, connConfig{
+		ServiceConfig: sc,
+		Keepalive: keepalive.ClientParameters{
+			Time:    *keepaliveTime,
+			Timeout: *keepaliveTimeout,
+			// 流式调用之间可能长时间没有请求，空闲时同样发送 ping 以便尽早发现断开的连接
+			PermitWithoutStream: true,
+		},
+		DialOptions: interceptor.DialOptions(cfg),
+	})
 	if err != nil {
 		log.Fatalf("连接失败: %v", err)
 	}
@@ -23,22 +78,76 @@ func main() {
 	// 创建一个Greeter服务的客户端
 	client := pb.NewGreeterClient(conn)
 
-	// 默认问候对象为"世界"，可以通过命令行参数覆盖
-	name := "世界"
-	if len(os.Args) > 1 {
-		name = os.Args[1]
+	// Ctrl+C 时取消正在进行的流式调用
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *verbose {
+		go logStateChanges(ctx, conn, cfg.Logger)
+	}
+	// 语言偏好通过元数据发送，对所有子命令生效；服务端优先使用请求中的 locale 字段
+	if *lang != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "accept-language", *lang)
 	}
 
-	// 创建一个带有超时的context，防止请求无限期等待
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+	if err := run(ctx, client, flag.Args()); err != nil {
+		printError(os.Stderr, err)
+		// os.Exit 不会执行 defer，先导出 span
+		tp.Shutdown(context.Background())
+		os.Exit(1)
+	}
+}
 
-	// 调用SayHello方法并处理响应
-	res, err := client.SayHello(ctx, &pb.HelloRequest{Name: name})
-	if err != nil {
-		log.Fatalf("请求失败: %v", err)
+// run 根据子命令调用对应的 RPC；第一个参数不是子命令时按 hello 处理，兼容 client [名字] 的用法
+func run(ctx context.Context, client pb.GreeterClient, args []string) error {
+	cmd := "hello"
+	if len(args) > 0 {
+		switch args[0] {
+		case "hello", "stream", "many", "chat":
+			cmd, args = args[0], args[1:]
+		case "help", "-h", "--help":
+			fmt.Print(usage)
+			return nil
+		}
 	}
 
-	// 输出接收到的响应消息
-	log.Printf("收到响应: %s", res.GetMessage())
+	// 默认问候对象为"世界"，可以通过命令行参数覆盖
+	name := "世界"
+	switch cmd {
+	case "hello":
+		if len(args) > 0 {
+			name = args[0]
+		}
+		// 超时、重试和等待连接就绪由 service config 中的 methodConfig 控制
+		return runHello(ctx, client, os.Stdout, name)
+
+	case "stream":
+		fs := flag.NewFlagSet("stream", flag.ExitOnError)
+		count := fs.Int("n", 3, "问候次数")
+		interval := fs.Duration("interval", 500*time.Millisecond, "两次问候之间的间隔")
+		fs.Parse(args)
+		if fs.NArg() > 0 {
+			name = fs.Arg(0)
+		}
+		// 服务端把 0 当作默认次数，客户端不传 0，超时时间才能按实际发送的条数计算
+		if *count <= 0 || *interval < 0 {
+			return fmt.Errorf("-n 需要大于 0，-interval 不能为负数\n%s", usage)
+		}
+		// 超时时间按服务端发送完全部问候所需的时间计算
+		ctx, cancel := context.WithTimeout(ctx, time.Duration(*count)*(*interval)+time.Second)
+		defer cancel()
+		return runStream(ctx, client, os.Stdout, name, *count, *interval)
+
+	case "many":
+		if len(args) == 0 {
+			return fmt.Errorf("many 需要至少一个名字\n%s", usage)
+		}
+		return runMany(ctx, client, os.Stdout, args)
+
+	default: // chat
+		if len(args) > 0 {
+			name = args[0]
+		}
+		// 聊天持续到标准输入结束（Ctrl+D）或 Ctrl+C，不设置超时
+		return runChat(ctx, client, os.Stdin, os.Stdout, name)
+	}
 }
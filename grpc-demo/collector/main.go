@@ -0,0 +1,81 @@
+// collector 是本地调试用的 OTLP trace collector 替身：接收 OTLP/gRPC 导出的 span 并逐条打印，
+// 不需要部署完整的 OpenTelemetry Collector 就能查看服务端和客户端之间传递的链路。
+//
+//	go run ./collector -addr :4317
+//	go run ./server -trace-exporter otlp
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"flag"
+	"log"
+	"log/slog"
+	"net"
+	"os"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+)
+
+// collector 实现 OTLP TraceService，把收到的 span 写入日志
+type collector struct {
+	coltracepb.UnimplementedTraceServiceServer
+	logger *slog.Logger
+}
+
+// Export 打印一批 span，每个 span 一条日志
+func (c *collector) Export(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	for _, rs := range req.GetResourceSpans() {
+		service := "unknown"
+		for _, attr := range rs.GetResource().GetAttributes() {
+			if attr.GetKey() == "service.name" {
+				service = attr.GetValue().GetStringValue()
+			}
+		}
+		for _, ss := range rs.GetScopeSpans() {
+			for _, span := range ss.GetSpans() {
+				c.logSpan(ctx, service, span)
+			}
+		}
+	}
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func (c *collector) logSpan(ctx context.Context, service string, span *tracepb.Span) {
+	duration := time.Duration(span.GetEndTimeUnixNano() - span.GetStartTimeUnixNano())
+	attrs := []slog.Attr{
+		slog.String("service", service),
+		slog.String("name", span.GetName()),
+		slog.String("kind", span.GetKind().String()),
+		slog.String("trace_id", hex.EncodeToString(span.GetTraceId())),
+		slog.String("span_id", hex.EncodeToString(span.GetSpanId())),
+		slog.Duration("duration", duration),
+	}
+	if parent := span.GetParentSpanId(); len(parent) > 0 {
+		attrs = append(attrs, slog.String("parent_id", hex.EncodeToString(parent)))
+	}
+	if msg := span.GetStatus().GetMessage(); msg != "" {
+		attrs = append(attrs, slog.String("error", msg))
+	}
+	c.logger.LogAttrs(ctx, slog.LevelInfo, "span", attrs...)
+}
+
+func main() {
+	addr := flag.String("addr", ":4317", "OTLP/gRPC 监听地址")
+	flag.Parse()
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("监听失败: %v", err)
+	}
+	s := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(s, &collector{logger: slog.New(slog.NewTextHandler(os.Stdout, nil))})
+
+	log.Printf("OTLP collector 运行中: %s", lis.Addr())
+	if err := s.Serve(lis); err != nil {
+		log.Fatalf("服务失败: %v", err)
+	}
+}
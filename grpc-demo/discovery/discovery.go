@@ -0,0 +1,56 @@
+// Package discovery 为 gRPC 客户端提供服务发现和客户端负载均衡：
+// 除了 gRPC 内置的 dns:///host:port，还支持两种目标地址
+//
+//	static:///10.0.0.1:50051,10.0.0.2:50051   固定的后端列表
+//	file:///etc/greeter/backends              从文件读取后端列表，文件变化后自动更新
+//
+// 连接使用 round_robin 在全部后端之间轮询，并通过 grpc.health.v1 健康检查
+// 剔除不是 SERVING 的后端（后端没有注册健康检查服务时视为健康）：
+//
+//	conn, err := grpc.NewClient(discovery.Target(addr), append(discovery.DialOptions("greet.Greeter"), creds)...)
+package discovery
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/health" // 注册客户端健康检查
+)
+
+// DialOptions 返回注册了 static 和 file 解析器、使用 round_robin 并对 serviceName 做健康检查的客户端选项
+// serviceName 为空时检查服务端的整体状态，适用于不确定服务端是否为每个服务设置了健康状态的情况
+func DialOptions(serviceName string) []grpc.DialOption {
+	serviceConfig := fmt.Sprintf(`{
+	"loadBalancingConfig": [{"round_robin": {}}],
+	"healthCheckConfig": {"serviceName": %q}
+}`, serviceName)
+	return []grpc.DialOption{Resolvers(), grpc.WithDefaultServiceConfig(serviceConfig)}
+}
+
+// Resolvers 只注册 static 和 file 解析器，用于自行提供完整 service config 的客户端，
+// 这时 service config 中需要自己设置 loadBalancingConfig 和 healthCheckConfig
+func Resolvers() grpc.DialOption {
+	return grpc.WithResolvers(staticBuilder{}, fileBuilder{})
+}
+
+// Target 把命令行中的地址转换为 gRPC 目标：逗号分隔的多个地址转换为 static:///a,b,c，
+// 已经带有 scheme 的地址（static://、file://、dns:// 等）和单个地址原样返回
+func Target(addr string) string {
+	if !strings.Contains(addr, "://") && strings.Contains(addr, ",") {
+		return staticScheme + ":///" + addr
+	}
+	return addr
+}
+
+// splitAddrs 按逗号、空白和换行拆分地址列表，忽略空项和 # 开头的注释
+func splitAddrs(s string) []string {
+	var addrs []string
+	for _, line := range strings.Split(s, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		for _, addr := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\r' }) {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
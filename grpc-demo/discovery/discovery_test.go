@@ -0,0 +1,212 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
+	pb "grpc-demo/proto"
+)
+
+// addrGreeter 回复自己的监听地址，用于检查请求被分配到了哪个后端
+type addrGreeter struct {
+	pb.UnimplementedGreeterServer
+	addr string
+}
+
+func (g addrGreeter) SayHello(context.Context, *pb.HelloRequest) (*pb.HelloReply, error) {
+	return &pb.HelloReply{Message: g.addr}, nil
+}
+
+// backend 一个进程内的 Greeter 后端
+type backend struct {
+	addr   string
+	health *health.Server
+}
+
+// startBackends 在本机 TCP 端口上启动 n 个带健康检查的 Greeter 服务
+func startBackends(t *testing.T, n int) []backend {
+	t.Helper()
+	backends := make([]backend, n)
+	for i := range backends {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := grpc.NewServer()
+		hs := health.NewServer()
+		healthpb.RegisterHealthServer(s, hs)
+		hs.SetServingStatus(pb.Greeter_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+		pb.RegisterGreeterServer(s, addrGreeter{addr: lis.Addr().String()})
+		go s.Serve(lis)
+		t.Cleanup(s.Stop)
+		backends[i] = backend{addr: lis.Addr().String(), health: hs}
+	}
+	return backends
+}
+
+func newTestClient(t *testing.T, target string) pb.GreeterClient {
+	t.Helper()
+	opts := append(DialOptions(pb.Greeter_ServiceDesc.ServiceName), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewGreeterClient(conn)
+}
+
+// hits 连续调用 n 次，返回每次响应的后端地址（排序后）
+func hits(t *testing.T, client pb.GreeterClient, n int) []string {
+	t.Helper()
+	var got []string
+	for range n {
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		res, err := client.SayHello(ctx, &pb.HelloRequest{Name: "x"}, grpc.WaitForReady(true))
+		cancel()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, res.GetMessage())
+	}
+	slices.Sort(got)
+	return got
+}
+
+// eventually 每 10ms 检查一次 cond，1 秒内不成立时报告失败
+func eventually(t *testing.T, msg string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// spread 返回每个地址 k 次组成的排序列表，即 round_robin 对这些后端各分配 k 次请求
+func spread(k int, addrs ...string) []string {
+	var want []string
+	for _, addr := range addrs {
+		for range k {
+			want = append(want, addr)
+		}
+	}
+	slices.Sort(want)
+	return want
+}
+
+func TestStaticRoundRobin(t *testing.T) {
+	backends := startBackends(t, 3)
+	a, b, c := backends[0].addr, backends[1].addr, backends[2].addr
+	client := newTestClient(t, Target(a+","+b+","+c))
+
+	// 三个后端都就绪后，每 6 次请求中每个后端各分到 2 次
+	eventually(t, "请求没有均匀分配到三个后端", func() bool {
+		return slices.Equal(hits(t, client, 6), spread(2, a, b, c))
+	})
+
+	// 健康检查失败的后端不再分配请求，恢复后重新加入
+	backends[1].health.SetServingStatus(pb.Greeter_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+	eventually(t, "NOT_SERVING 的后端仍然收到请求", func() bool {
+		return slices.Equal(hits(t, client, 4), spread(2, a, c))
+	})
+	backends[1].health.SetServingStatus(pb.Greeter_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	eventually(t, "恢复 SERVING 的后端没有重新收到请求", func() bool {
+		return slices.Equal(hits(t, client, 6), spread(2, a, b, c))
+	})
+}
+
+func TestFileResolver(t *testing.T) {
+	old := refreshInterval
+	refreshInterval = 10 * time.Millisecond
+	t.Cleanup(func() { refreshInterval = old })
+
+	backends := startBackends(t, 3)
+	a, b, c := backends[0].addr, backends[1].addr, backends[2].addr
+	path := filepath.Join(t.TempDir(), "backends")
+	if err := os.WriteFile(path, []byte("# 后端列表\n"+a+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, "file://"+path)
+	if got := hits(t, client, 3); !slices.Equal(got, spread(3, a)) {
+		t.Errorf("got %q, want only %s", got, a)
+	}
+
+	// 文件更新后请求转移到新的后端
+	if err := os.WriteFile(path, []byte(b+", "+c+"  # 扩容\n\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "文件更新后请求没有转移到新的后端", func() bool {
+		return slices.Equal(hits(t, client, 4), spread(2, b, c))
+	})
+
+	// 文件暂时消失时继续使用原来的后端
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * refreshInterval)
+	if got := hits(t, client, 4); !slices.Equal(got, spread(2, b, c)) {
+		t.Errorf("文件删除后 got %q", got)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	parse := func(s string) resolver.Target {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resolver.Target{URL: *u}
+	}
+	empty := filepath.Join(t.TempDir(), "empty")
+	os.WriteFile(empty, []byte("# 没有地址\n"), 0o644)
+
+	tests := []struct {
+		builder resolver.Builder
+		target  string
+		want    string
+	}{
+		{staticBuilder{}, "static:///", "没有地址"},
+		{staticBuilder{}, "static:///,,", "没有地址"},
+		{fileBuilder{}, "file://", "没有路径"},
+		{fileBuilder{}, "file://" + filepath.Join(t.TempDir(), "missing"), "no such file"},
+		{fileBuilder{}, "file://" + empty, "没有地址"},
+	}
+	for _, tt := range tests {
+		_, err := tt.builder.Build(parse(tt.target), nil, resolver.BuildOptions{})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %q", tt.target, err, tt.want)
+		}
+	}
+}
+
+func TestTarget(t *testing.T) {
+	tests := []struct{ addr, want string }{
+		{"localhost:50051", "localhost:50051"},
+		{"a:1,b:2", "static:///a:1,b:2"},
+		{"static:///a:1,b:2", "static:///a:1,b:2"},
+		{"file:///etc/backends", "file:///etc/backends"},
+		{"dns:///greeter:50051", "dns:///greeter:50051"},
+	}
+	for _, tt := range tests {
+		if got := Target(tt.addr); got != tt.want {
+			t.Errorf("Target(%q) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+	if got := splitAddrs("a:1, b:2\n# c:3\n\td:4 # e:5\r\n"); !slices.Equal(got, []string{"a:1", "b:2", "d:4"}) {
+		t.Errorf("splitAddrs = %q", got)
+	}
+}
@@ -0,0 +1,114 @@
+package discovery
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/grpclog"
+	"google.golang.org/grpc/resolver"
+)
+
+// fileScheme 从文件读取后端列表的 scheme，目标形如 file:///etc/greeter/backends
+const fileScheme = "file"
+
+// refreshInterval 重新读取后端列表文件的间隔，测试中改小
+var refreshInterval = 5 * time.Second
+
+var logger = grpclog.Component("discovery")
+
+// fileBuilder 解析 file:///path。文件中每行一个或多个（逗号分隔）地址，# 之后为注释
+type fileBuilder struct{}
+
+func (fileBuilder) Scheme() string { return fileScheme }
+
+func (fileBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	// file:///abs/path 的路径在 URL.Path 中，file://./rel/path 的开头在 URL.Host 中
+	path := target.URL.Host + target.URL.Path
+	if path == "" {
+		return nil, errors.New("discovery: file 目标没有路径: " + target.String())
+	}
+	r := &fileResolver{
+		path: path,
+		cc:   cc,
+		now:  make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	// 第一次读取失败时直接返回错误，避免客户端一直等待一个不存在的文件
+	addrs, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	r.addrs = addrs
+	if err := cc.UpdateState(newState(addrs)); err != nil {
+		return nil, err
+	}
+	r.wg.Add(1)
+	go r.watch()
+	return r, nil
+}
+
+// fileResolver 定期重新读取文件，地址列表变化时通知 gRPC
+type fileResolver struct {
+	path  string
+	cc    resolver.ClientConn
+	addrs []string // 上次通知的地址，只由 watch 修改
+
+	now  chan struct{} // ResolveNow 触发立即重新读取
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// read 读取文件中的地址列表，文件为空视为错误
+func (r *fileResolver) read() ([]string, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return nil, err
+	}
+	addrs := splitAddrs(string(data))
+	if len(addrs) == 0 {
+		return nil, errors.New("discovery: " + r.path + " 中没有地址")
+	}
+	return addrs, nil
+}
+
+func (r *fileResolver) watch() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		case <-r.now:
+		}
+		addrs, err := r.read()
+		if err != nil {
+			// 文件暂时不可读（如正在被替换）时保留原来的地址
+			logger.Warningf("读取 %s 失败，继续使用原来的 %d 个地址: %v", r.path, len(r.addrs), err)
+			continue
+		}
+		if slices.Equal(addrs, r.addrs) {
+			continue
+		}
+		logger.Infof("%s 中的地址变为 %v", r.path, addrs)
+		r.addrs = addrs
+		r.cc.UpdateState(newState(addrs))
+	}
+}
+
+// ResolveNow 在连接失败等情况下由 gRPC 调用，立即重新读取文件
+func (r *fileResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.now <- struct{}{}:
+	default:
+	}
+}
+
+func (r *fileResolver) Close() {
+	close(r.done)
+	r.wg.Wait()
+}
@@ -0,0 +1,41 @@
+package discovery
+
+import (
+	"errors"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// staticScheme 固定后端列表的 scheme，目标形如 static:///a:50051,b:50051
+const staticScheme = "static"
+
+// staticBuilder 解析 static:///a,b,c，地址在创建连接时确定，之后不再变化
+type staticBuilder struct{}
+
+func (staticBuilder) Scheme() string { return staticScheme }
+
+func (staticBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	addrs := splitAddrs(target.Endpoint())
+	if len(addrs) == 0 {
+		return nil, errors.New("discovery: static 目标没有地址: " + target.String())
+	}
+	if err := cc.UpdateState(newState(addrs)); err != nil {
+		return nil, err
+	}
+	return staticResolver{}, nil
+}
+
+// staticResolver 地址固定，重新解析时无事可做
+type staticResolver struct{}
+
+func (staticResolver) ResolveNow(resolver.ResolveNowOptions) {}
+func (staticResolver) Close()                                {}
+
+// newState 为每个地址创建一个 endpoint，round_robin 在 endpoint 之间轮询
+func newState(addrs []string) resolver.State {
+	endpoints := make([]resolver.Endpoint, len(addrs))
+	for i, addr := range addrs {
+		endpoints[i] = resolver.Endpoint{Addresses: []resolver.Address{{Addr: addr}}}
+	}
+	return resolver.State{Endpoints: endpoints}
+}
@@ -0,0 +1,153 @@
+// Package interceptor 提供 gRPC 服务端和客户端共用的拦截器：
+// 结构化日志（slog）、Prometheus 直方图、OpenTelemetry 链路追踪，以及服务端的请求校验。
+//
+// 服务端使用 ServerOptions，客户端使用 DialOptions，一次性装好全部拦截器：
+//
+//	s := grpc.NewServer(interceptor.ServerOptions(cfg)...)
+//	conn, err := grpc.NewClient(addr, append(interceptor.DialOptions(cfg), creds)...)
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Config 拦截器配置，字段为零值时不启用对应的功能
+type Config struct {
+	// Logger 记录每次调用的方法、状态码和耗时
+	Logger *slog.Logger
+	// Metrics 记录调用耗时的直方图
+	Metrics *Metrics
+	// TracerProvider 创建调用的 span，并通过 metadata 传递链路上下文
+	TracerProvider trace.TracerProvider
+	// Validation 在服务端校验实现了 Validator 的请求，只对 ServerOptions 有效
+	Validation bool
+}
+
+// ServerOptions 返回装有全部已启用拦截器的服务端选项
+// 执行顺序为追踪、指标、日志、校验：日志中可以带上当前调用的 trace_id，校验失败的请求同样被记录
+func ServerOptions(cfg Config) []grpc.ServerOption {
+	var unary []grpc.UnaryServerInterceptor
+	var stream []grpc.StreamServerInterceptor
+	if cfg.TracerProvider != nil {
+		unary = append(unary, UnaryServerTracing(cfg.TracerProvider))
+		stream = append(stream, StreamServerTracing(cfg.TracerProvider))
+	}
+	if cfg.Metrics != nil {
+		unary = append(unary, cfg.Metrics.UnaryServerInterceptor())
+		stream = append(stream, cfg.Metrics.StreamServerInterceptor())
+	}
+	if cfg.Logger != nil {
+		unary = append(unary, UnaryServerLogging(cfg.Logger))
+		stream = append(stream, StreamServerLogging(cfg.Logger))
+	}
+	if cfg.Validation {
+		unary = append(unary, UnaryServerValidation())
+		stream = append(stream, StreamServerValidation())
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+}
+
+// DialOptions 返回装有全部已启用拦截器的客户端选项，执行顺序与 ServerOptions 相同
+func DialOptions(cfg Config) []grpc.DialOption {
+	var unary []grpc.UnaryClientInterceptor
+	var stream []grpc.StreamClientInterceptor
+	if cfg.TracerProvider != nil {
+		unary = append(unary, UnaryClientTracing(cfg.TracerProvider))
+		stream = append(stream, StreamClientTracing(cfg.TracerProvider))
+	}
+	if cfg.Metrics != nil {
+		unary = append(unary, cfg.Metrics.UnaryClientInterceptor())
+		stream = append(stream, cfg.Metrics.StreamClientInterceptor())
+	}
+	if cfg.Logger != nil {
+		unary = append(unary, UnaryClientLogging(cfg.Logger))
+		stream = append(stream, StreamClientLogging(cfg.Logger))
+	}
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(unary...),
+		grpc.WithChainStreamInterceptor(stream...),
+	}
+}
+
+// splitMethod 把 /greet.Greeter/SayHello 形式的完整方法名拆分为服务名和方法名
+func splitMethod(fullMethod string) (service, method string) {
+	name := strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "unknown", name
+}
+
+// rpcType 返回调用类型，用作指标标签
+func rpcType(clientStream, serverStream bool) string {
+	switch {
+	case clientStream && serverStream:
+		return "bidi_stream"
+	case clientStream:
+		return "client_stream"
+	case serverStream:
+		return "server_stream"
+	default:
+		return "unary"
+	}
+}
+
+// wrappedServerStream 替换 Context 的服务端流，用于把追踪拦截器创建的 span 传给后续的处理函数
+type wrappedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedServerStream) Context() context.Context { return s.ctx }
+
+// clientStream 在客户端流结束时调用 done：收到服务端的状态（RecvMsg 返回错误），
+// 或者非服务端流式的调用收到唯一的回复。客户端放弃读取的流不会被记录
+type clientStream struct {
+	grpc.ClientStream
+	desc *grpc.StreamDesc
+	once sync.Once
+	done func(err error)
+}
+
+// newClientStream 调用 streamer 创建流，创建失败时直接以该错误结束
+func newClientStream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+	streamer grpc.Streamer, done func(err error), opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		done(err)
+		return nil, err
+	}
+	return &clientStream{ClientStream: cs, desc: desc, done: done}, nil
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil || !s.desc.ServerStreams {
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *clientStream) finish(err error) {
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	s.once.Do(func() { s.done(err) })
+}
+
+// code 返回错误对应的 gRPC 状态码名称
+func code(err error) string {
+	return status.Code(err).String()
+}
@@ -0,0 +1,256 @@
+package interceptor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	pb "grpc-demo/proto"
+)
+
+// greeter 名字为空时返回 InvalidArgument 的最小 Greeter 实现
+type greeter struct {
+	pb.UnimplementedGreeterServer
+}
+
+func (greeter) SayHello(_ context.Context, req *pb.HelloRequest) (*pb.HelloReply, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name 不能为空")
+	}
+	return &pb.HelloReply{Message: "hello " + req.GetName()}, nil
+}
+
+func (greeter) SayHelloToMany(stream grpc.ClientStreamingServer[pb.HelloRequest, pb.ManyHelloReply]) error {
+	var names []string
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&pb.ManyHelloReply{Names: names})
+		}
+		if err != nil {
+			return err
+		}
+		names = append(names, req.GetName())
+	}
+}
+
+// syncBuffer 可以被服务端和客户端的日志并发写入的缓冲区
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// records 解析 JSON 格式的日志
+func (b *syncBuffer) records(t *testing.T) []map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var recs []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("解析日志 %q: %v", line, err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+type testEnv struct {
+	client  pb.GreeterClient
+	logs    *syncBuffer
+	metrics *Metrics
+	spans   *tracetest.SpanRecorder
+}
+
+// newTestEnv 在内存连接上启动装有全部拦截器的服务端，客户端同样装有全部拦截器，两端共用日志、指标和 span 记录
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	env := &testEnv{logs: &syncBuffer{}, metrics: NewMetrics(), spans: tracetest.NewSpanRecorder()}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(env.spans))
+	cfg := Config{
+		Logger:         slog.New(slog.NewJSONHandler(env.logs, nil)),
+		Metrics:        env.metrics,
+		TracerProvider: tp,
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(ServerOptions(cfg)...)
+	pb.RegisterGreeterServer(s, greeter{})
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	opts := append(DialOptions(cfg),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	env.client = pb.NewGreeterClient(conn)
+	return env
+}
+
+// eventually 等待 cond 成立：服务端拦截器在回复发出后才记录，客户端收到回复时服务端可能还没有记录完
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (env *testEnv) sayHelloToMany(t *testing.T, names ...string) {
+	t.Helper()
+	stream, err := env.client.SayHelloToMany(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if err := stream.Send(&pb.HelloRequest{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := stream.CloseAndRecv(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLogging(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.client.SayHello(t.Context(), &pb.HelloRequest{Name: "张三"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.client.SayHello(t.Context(), &pb.HelloRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("err = %v", err)
+	}
+	env.sayHelloToMany(t, "a", "b")
+	eventually(t, func() bool { return len(env.logs.records(t)) >= 6 })
+
+	type key struct{ side, method, code, level string }
+	got := map[key]map[string]any{}
+	for _, rec := range env.logs.records(t) {
+		got[key{rec["side"].(string), rec["method"].(string), rec["code"].(string), rec["level"].(string)}] = rec
+	}
+	for _, want := range []key{
+		{"server", "SayHello", "OK", "INFO"},
+		{"client", "SayHello", "OK", "INFO"},
+		{"server", "SayHello", "InvalidArgument", "WARN"},
+		{"client", "SayHello", "InvalidArgument", "WARN"},
+		{"server", "SayHelloToMany", "OK", "INFO"},
+		{"client", "SayHelloToMany", "OK", "INFO"},
+	} {
+		rec, ok := got[want]
+		if !ok {
+			t.Errorf("缺少日志 %+v，全部日志: %v", want, env.logs.records(t))
+			continue
+		}
+		if rec["service"] != "greet.Greeter" || rec["latency"] == nil || rec["trace_id"] == nil {
+			t.Errorf("日志字段不完整: %v", rec)
+		}
+		if want.code != "OK" && rec["error"] != "name 不能为空" {
+			t.Errorf("错误日志缺少 error: %v", rec)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	env := newTestEnv(t)
+	for range 2 {
+		if _, err := env.client.SayHello(t.Context(), &pb.HelloRequest{Name: "张三"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env.client.SayHello(t.Context(), &pb.HelloRequest{})
+	env.sayHelloToMany(t, "a")
+
+	var body string
+	eventually(t, func() bool {
+		rec := httptest.NewRecorder()
+		env.metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		body = rec.Body.String()
+		return strings.Contains(body, `grpc_server_handling_seconds_count{grpc_code="OK",grpc_method="SayHelloToMany"`)
+	})
+	for _, want := range []string{
+		`grpc_server_handling_seconds_count{grpc_code="OK",grpc_method="SayHello",grpc_service="greet.Greeter",grpc_type="unary"} 2`,
+		`grpc_server_handling_seconds_count{grpc_code="InvalidArgument",grpc_method="SayHello",grpc_service="greet.Greeter",grpc_type="unary"} 1`,
+		`grpc_client_handling_seconds_count{grpc_code="OK",grpc_method="SayHello",grpc_service="greet.Greeter",grpc_type="unary"} 2`,
+		`grpc_server_handling_seconds_count{grpc_code="OK",grpc_method="SayHelloToMany",grpc_service="greet.Greeter",grpc_type="client_stream"} 1`,
+		`grpc_client_handling_seconds_count{grpc_code="OK",grpc_method="SayHelloToMany",grpc_service="greet.Greeter",grpc_type="client_stream"} 1`,
+		`grpc_server_handling_seconds_bucket{grpc_code="OK",grpc_method="SayHello",grpc_service="greet.Greeter",grpc_type="unary",le="+Inf"} 2`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics 缺少 %s", want)
+		}
+	}
+}
+
+func TestTracingPropagation(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.client.SayHello(t.Context(), &pb.HelloRequest{}); err == nil {
+		t.Fatal("空名字应当返回错误")
+	}
+	env.sayHelloToMany(t, "a", "b")
+	eventually(t, func() bool { return len(env.spans.Ended()) >= 4 })
+
+	spans := env.spans.Ended()
+	byKind := map[string]map[trace.SpanKind]sdktrace.ReadOnlySpan{}
+	for _, s := range spans {
+		if byKind[s.Name()] == nil {
+			byKind[s.Name()] = map[trace.SpanKind]sdktrace.ReadOnlySpan{}
+		}
+		byKind[s.Name()][s.SpanKind()] = s
+	}
+	for _, name := range []string{"greet.Greeter/SayHello", "greet.Greeter/SayHelloToMany"} {
+		client, server := byKind[name][trace.SpanKindClient], byKind[name][trace.SpanKindServer]
+		if client == nil || server == nil {
+			t.Fatalf("%s: 缺少 span，全部 span: %v", name, spans)
+		}
+		// 服务端 span 的父 span 是通过 metadata 传递过来的客户端 span
+		if server.Parent().SpanID() != client.SpanContext().SpanID() || !server.Parent().IsRemote() ||
+			server.SpanContext().TraceID() != client.SpanContext().TraceID() {
+			t.Errorf("%s: 服务端 span 没有接上客户端的链路: parent=%v client=%v", name, server.Parent(), client.SpanContext())
+		}
+	}
+	if st := byKind["greet.Greeter/SayHello"][trace.SpanKindServer].Status(); st.Description != "name 不能为空" {
+		t.Errorf("失败调用的 span status = %+v", st)
+	}
+}
+
+func TestNewTracerProvider(t *testing.T) {
+	for _, exporter := range []string{"", "none", "stdout", "otlp"} {
+		tp, err := NewTracerProvider(t.Context(), "test", exporter, "localhost:4317")
+		if err != nil {
+			t.Errorf("%q: %v", exporter, err)
+			continue
+		}
+		tp.Shutdown(t.Context())
+	}
+	if _, err := NewTracerProvider(t.Context(), "test", "jaeger", ""); err == nil {
+		t.Error("不支持的 exporter 应当返回错误")
+	}
+}
@@ -0,0 +1,81 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerLogging 返回记录一元调用的服务端拦截器
+func UnaryServerLogging(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, logger, "server", info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerLogging 返回记录流式调用的服务端拦截器，流结束时记录一条日志
+func StreamServerLogging(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), logger, "server", info.FullMethod, start, err)
+		return err
+	}
+}
+
+// UnaryClientLogging 返回记录一元调用的客户端拦截器
+func UnaryClientLogging(logger *slog.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		logCall(ctx, logger, "client", method, start, err)
+		return err
+	}
+}
+
+// StreamClientLogging 返回记录流式调用的客户端拦截器，收到服务端的最终状态时记录一条日志
+func StreamClientLogging(logger *slog.Logger) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		return newClientStream(ctx, desc, cc, method, streamer, func(err error) {
+			logCall(ctx, logger, "client", method, start, err)
+		}, opts...)
+	}
+}
+
+// logCall 记录一次调用：成功为 Info，服务端错误为 Error，其他错误为 Warn
+func logCall(ctx context.Context, logger *slog.Logger, side, fullMethod string, start time.Time, err error) {
+	service, method := splitMethod(fullMethod)
+	attrs := []slog.Attr{
+		slog.String("side", side),
+		slog.String("service", service),
+		slog.String("method", method),
+		slog.String("code", code(err)),
+		slog.Duration("latency", time.Since(start)),
+	}
+	if p, ok := peer.FromContext(ctx); ok && side == "server" {
+		attrs = append(attrs, slog.String("peer", p.Addr.String()))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		attrs = append(attrs, slog.String("trace_id", sc.TraceID().String()))
+	}
+	level := slog.LevelInfo
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+		level = slog.LevelWarn
+		switch status.Code(err) {
+		case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unimplemented:
+			level = slog.LevelError
+		}
+	}
+	logger.LogAttrs(ctx, level, "gRPC 调用", attrs...)
+}
@@ -0,0 +1,104 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+)
+
+// Metrics 以 Prometheus 直方图记录 gRPC 调用耗时，标签为调用类型、服务、方法和状态码
+type Metrics struct {
+	registry *prometheus.Registry
+	server   *prometheus.HistogramVec
+	client   *prometheus.HistogramVec
+}
+
+// NewMetrics 创建指标并注册到新的 Registry，同时注册 Go 运行时和进程指标
+func NewMetrics() *Metrics {
+	labels := []string{"grpc_type", "grpc_service", "grpc_method", "grpc_code"}
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		server: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "服务端处理 gRPC 调用的耗时（秒）",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+		client: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_client_handling_seconds",
+			Help:    "客户端发起 gRPC 调用到收到最终状态的耗时（秒）",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+	}
+	m.registry.MustRegister(m.server, m.client,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return m
+}
+
+// Handler 返回以 Prometheus 文本格式输出指标的 HTTP 处理器，通常挂在 /metrics 上
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ListenAndServe 在 addr 上提供 /metrics，阻塞直到服务出错
+func (m *Metrics) ListenAndServe(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	err := http.ListenAndServe(addr, mux)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (m *Metrics) observe(h *prometheus.HistogramVec, typ, fullMethod string, start time.Time, err error) {
+	service, method := splitMethod(fullMethod)
+	h.WithLabelValues(typ, service, method, code(err)).Observe(time.Since(start).Seconds())
+}
+
+// UnaryServerInterceptor 返回记录一元调用耗时的服务端拦截器
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observe(m.server, "unary", info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor 返回记录流式调用耗时的服务端拦截器
+func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.observe(m.server, rpcType(info.IsClientStream, info.IsServerStream), info.FullMethod, start, err)
+		return err
+	}
+}
+
+// UnaryClientInterceptor 返回记录一元调用耗时的客户端拦截器
+func (m *Metrics) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		m.observe(m.client, "unary", method, start, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor 返回记录流式调用耗时的客户端拦截器
+func (m *Metrics) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		typ := rpcType(desc.ClientStreams, desc.ServerStreams)
+		return newClientStream(ctx, desc, cc, method, streamer, func(err error) {
+			m.observe(m.client, typ, method, start, err)
+		}, opts...)
+	}
+}
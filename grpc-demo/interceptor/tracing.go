@@ -0,0 +1,148 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tracerName 拦截器创建 span 时使用的 instrumentation 名称
+const tracerName = "grpc-demo/interceptor"
+
+// propagator 通过 W3C traceparent/tracestate 和 baggage 在 metadata 中传递链路上下文
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// NewTracerProvider 创建把 span 导出到 exporter 的 TracerProvider：
+// "stdout" 输出到标准输出，"otlp" 通过 gRPC 发送到 endpoint 上的 OTLP collector（例如本仓库的 collector 命令），
+// "" 或 "none" 不导出，但仍然生成和传递链路上下文。使用结束后需要调用 Shutdown 导出剩余的 span
+func NewTracerProvider(ctx context.Context, serviceName, exporter, endpoint string) (*sdktrace.TracerProvider, error) {
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	}
+	switch strings.ToLower(exporter) {
+	case "", "none":
+	case "stdout":
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	case "otlp":
+		exp, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(endpoint), otlptracegrpc.WithInsecure())
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	default:
+		return nil, fmt.Errorf("interceptor: 不支持的 trace exporter %q，可选 stdout、otlp、none", exporter)
+	}
+	return sdktrace.NewTracerProvider(opts...), nil
+}
+
+// metadataCarrier 把 gRPC metadata 适配为 propagation.TextMapCarrier
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) { metadata.MD(c).Set(key, value) }
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// startServerSpan 从请求的 metadata 中提取上游的链路上下文，创建服务端 span
+func startServerSpan(ctx context.Context, tp trace.TracerProvider, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = propagator.Extract(ctx, metadataCarrier(md))
+	return tp.Tracer(tracerName).Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(rpcAttributes(fullMethod)...))
+}
+
+// startClientSpan 创建客户端 span，并把链路上下文写入请求的 metadata
+func startClientSpan(ctx context.Context, tp trace.TracerProvider, fullMethod string) (context.Context, trace.Span) {
+	ctx, span := tp.Tracer(tracerName).Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(rpcAttributes(fullMethod)...))
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	propagator.Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+func rpcAttributes(fullMethod string) []attribute.KeyValue {
+	service, method := splitMethod(fullMethod)
+	return []attribute.KeyValue{
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", method),
+	}
+}
+
+// endSpan 记录调用的状态码并结束 span
+func endSpan(span trace.Span, err error) {
+	st := status.Convert(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(st.Code())))
+	if err != nil {
+		span.SetStatus(otelcodes.Error, st.Message())
+	}
+	span.End()
+}
+
+// UnaryServerTracing 返回为一元调用创建 span 的服务端拦截器
+func UnaryServerTracing(tp trace.TracerProvider) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, span := startServerSpan(ctx, tp, info.FullMethod)
+		resp, err := handler(ctx, req)
+		endSpan(span, err)
+		return resp, err
+	}
+}
+
+// StreamServerTracing 返回为流式调用创建 span 的服务端拦截器，处理函数通过 stream.Context() 获得 span
+func StreamServerTracing(tp trace.TracerProvider) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startServerSpan(ss.Context(), tp, info.FullMethod)
+		err := handler(srv, &wrappedServerStream{ServerStream: ss, ctx: ctx})
+		endSpan(span, err)
+		return err
+	}
+}
+
+// UnaryClientTracing 返回为一元调用创建 span 并传递链路上下文的客户端拦截器
+func UnaryClientTracing(tp trace.TracerProvider) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := startClientSpan(ctx, tp, method)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		endSpan(span, err)
+		return err
+	}
+}
+
+// StreamClientTracing 返回为流式调用创建 span 并传递链路上下文的客户端拦截器
+func StreamClientTracing(tp trace.TracerProvider) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := startClientSpan(ctx, tp, method)
+		return newClientStream(ctx, desc, cc, method, streamer, func(err error) { endSpan(span, err) }, opts...)
+	}
+}
@@ -0,0 +1,62 @@
+package interceptor
+
+import (
+	"context"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Validator 由需要校验的请求消息实现，返回全部不合法的字段，合法时返回空
+type Validator interface {
+	Validate() []*errdetails.BadRequest_FieldViolation
+}
+
+// validate 校验实现了 Validator 的消息，不合法时返回带 BadRequest 详情的 InvalidArgument
+func validate(m any) error {
+	v, ok := m.(Validator)
+	if !ok {
+		return nil
+	}
+	violations := v.Validate()
+	if len(violations) == 0 {
+		return nil
+	}
+	st, err := status.New(codes.InvalidArgument, "请求参数不合法").
+		WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "请求参数不合法")
+	}
+	return st.Err()
+}
+
+// UnaryServerValidation 返回在调用处理函数之前校验请求的服务端拦截器
+func UnaryServerValidation() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := validate(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerValidation 返回校验流中每条请求的服务端拦截器：不合法的消息使 Recv 返回 InvalidArgument，
+// 处理函数按惯例返回 Recv 的错误即可结束调用
+func StreamServerValidation() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingServerStream{ServerStream: ss})
+	}
+}
+
+type validatingServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validate(m)
+}
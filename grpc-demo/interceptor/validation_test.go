@@ -0,0 +1,116 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	pb "grpc-demo/proto"
+)
+
+// violations 取出错误中 BadRequest 详情的 字段: 描述 列表
+func violations(t *testing.T, err error) []string {
+	t.Helper()
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code = %v, want InvalidArgument (err = %v)", st.Code(), err)
+	}
+	var got []string
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				got = append(got, v.GetField()+": "+v.GetDescription())
+			}
+		}
+	}
+	return got
+}
+
+func TestUnaryServerValidation(t *testing.T) {
+	called := false
+	handler := func(context.Context, any) (any, error) {
+		called = true
+		return &pb.HelloReply{}, nil
+	}
+	validate := UnaryServerValidation()
+	info := &grpc.UnaryServerInfo{FullMethod: "/greet.Greeter/SayHello"}
+
+	tests := []struct {
+		req  any
+		want string
+	}{
+		{&pb.HelloRequest{}, "name: 不能为空"},
+		{&pb.HelloRequest{Name: "\xff"}, "name: 不是合法的 UTF-8 字符串"},
+		{&pb.HelloRequest{Name: strings.Repeat("名", pb.MaxNameLength+1)}, "name: 长度不能超过 64 个字符"},
+		{&pb.ChatMessage{From: "张三"}, "text: 不能为空"},
+		{&pb.HelloRequest{Name: "张三", Locale: strings.Repeat("en,", pb.MaxLocaleLength/3+1)}, "locale: 长度不能超过 128 个字节"},
+		{&pb.HelloRequest{Name: "张三", Locale: "zh_CN"}, `locale: "zh_CN" 不是合法的语言标签`},
+		{&pb.HelloRequest{Name: "张三", Locale: "en,<script>"}, `locale: "<script>" 不是合法的语言标签`},
+		{&pb.HelloRequest{Name: "张三", Locale: "en;q=2"}, `locale: "q=2" 不是合法的权重`},
+		{&pb.HelloStreamRequest{Name: "张三", Locale: "toolongtag"}, `locale: "toolongtag" 不是合法的语言标签`},
+	}
+	for _, tt := range tests {
+		_, err := validate(t.Context(), tt.req, info, handler)
+		if got := violations(t, err); len(got) != 1 || got[0] != tt.want {
+			t.Errorf("%v: violations = %q, want %q", tt.req, got, tt.want)
+		}
+	}
+	if _, err := validate(t.Context(), &pb.ChatMessage{}, info, handler); len(violations(t, err)) != 2 {
+		t.Errorf("两个字段都不合法时应当列出两条: %v", err)
+	}
+	if called {
+		t.Error("不合法的请求不应当调用处理函数")
+	}
+
+	// 合法的请求和没有实现 Validator 的请求直接交给处理函数
+	for _, req := range []any{
+		&pb.HelloRequest{Name: strings.Repeat("名", pb.MaxNameLength)},
+		&pb.HelloRequest{Name: "张三", Locale: "zh-Hans-CN, en;q=0.8, *;q=0.1"},
+		&pb.HelloStreamRequest{Name: "张三", Locale: "ja"},
+		&pb.ManyHelloReply{},
+	} {
+		called = false
+		if _, err := validate(t.Context(), req, info, handler); err != nil || !called {
+			t.Errorf("%v: err = %v, called = %v", req, err, called)
+		}
+	}
+}
+
+func TestStreamServerValidation(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(ServerOptions(Config{Validation: true})...)
+	pb.RegisterGreeterServer(s, greeter{})
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := pb.NewGreeterClient(conn)
+
+	// 流中的第二个名字为空，服务端的 Recv 返回错误后调用结束
+	stream, err := client.SayHelloToMany(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Send(&pb.HelloRequest{Name: "张三"})
+	stream.Send(&pb.HelloRequest{})
+	_, err = stream.CloseAndRecv()
+	if got := violations(t, err); len(got) != 1 || got[0] != "name: 不能为空" {
+		t.Errorf("violations = %q", got)
+	}
+
+	if _, err := client.SayHello(t.Context(), &pb.HelloRequest{}); len(violations(t, err)) != 1 {
+		t.Errorf("一元调用没有被校验: %v", err)
+	}
+}
@@ -7,29 +7,34 @@
 package proto
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
 )
 
 const (
-	// 验证生成的代码版本是否足够新
+	// Verify that this generated code is sufficiently up-to-date.
 	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
-	// 验证 runtime/protoimpl 是否版本足够新
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-// HelloRequest 定义了问候请求的结构体
+// HelloRequest 问候请求
 type HelloRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // 请求中包含的名字字段
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 被问候的名字
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// 问候语的语言，格式同 HTTP Accept-Language，如 "en" 或 "zh-CN,en;q=0.8"。
+	// 为空时使用请求元数据中的 accept-language，都没有时使用服务端的默认语言
+	Locale        string `protobuf:"bytes,2,opt,name=locale,proto3" json:"locale,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-// Reset 方法用于重置 HelloRequest 消息的状态
 func (x *HelloRequest) Reset() {
 	*x = HelloRequest{}
 	mi := &file_proto_greet_proto_msgTypes[0]
@@ -37,15 +42,12 @@ func (x *HelloRequest) Reset() {
 	ms.StoreMessageInfo(mi)
 }
 
-// String 方法返回 HelloRequest 的字符串表示
 func (x *HelloRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-// ProtoMessage 标记函数，标识这是一个 Protocol Buffer 消息
 func (*HelloRequest) ProtoMessage() {}
 
-// ProtoReflect 返回 HelloRequest 的反射信息
 func (x *HelloRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_greet_proto_msgTypes[0]
 	if x != nil {
@@ -58,13 +60,11 @@ func (x *HelloRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Descriptor 返回描述 HelloRequest 结构的原始字节描述和路径
 // Deprecated: Use HelloRequest.ProtoReflect.Descriptor instead.
 func (*HelloRequest) Descriptor() ([]byte, []int) {
 	return file_proto_greet_proto_rawDescGZIP(), []int{0}
 }
 
-// GetName 获取 Name 字段的值，如果对象为 nil 则返回空字符串
 func (x *HelloRequest) GetName() string {
 	if x != nil {
 		return x.Name
@@ -72,15 +72,24 @@ func (x *HelloRequest) GetName() string {
 	return ""
 }
 
-// HelloReply 定义了问候回复的结构体
+func (x *HelloRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+// HelloReply 问候回复
 type HelloReply struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"` // 回复中包含的消息字段
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 问候语
+	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// 问候语实际使用的语言
+	Locale        string `protobuf:"bytes,2,opt,name=locale,proto3" json:"locale,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-// Reset 方法用于重置 HelloReply 消息的状态
 func (x *HelloReply) Reset() {
 	*x = HelloReply{}
 	mi := &file_proto_greet_proto_msgTypes[1]
@@ -88,15 +97,12 @@ func (x *HelloReply) Reset() {
 	ms.StoreMessageInfo(mi)
 }
 
-// String 方法返回 HelloReply 的字符串表示
 func (x *HelloReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-// ProtoMessage 标记函数，标识这是一个 Protocol Buffer 消息
 func (*HelloReply) ProtoMessage() {}
 
-// ProtoReflect 返回 HelloReply 的反射信息
 func (x *HelloReply) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_greet_proto_msgTypes[1]
 	if x != nil {
@@ -109,13 +115,11 @@ func (x *HelloReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Descriptor 返回描述 HelloReply 结构的原始字节描述和路径
 // Deprecated: Use HelloReply.ProtoReflect.Descriptor instead.
 func (*HelloReply) Descriptor() ([]byte, []int) {
 	return file_proto_greet_proto_rawDescGZIP(), []int{1}
 }
 
-// GetMessage 获取 Message 字段的值，如果对象为 nil 则返回空字符串
 func (x *HelloReply) GetMessage() string {
 	if x != nil {
 		return x.Message
@@ -123,26 +127,240 @@ func (x *HelloReply) GetMessage() string {
 	return ""
 }
 
+func (x *HelloReply) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+// HelloStreamRequest 重复问候请求
+type HelloStreamRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 被问候的名字
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// 问候的次数，为 0 时使用服务端默认值
+	Count int32 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	// 两次问候之间的间隔，为空时不等待
+	Interval *durationpb.Duration `protobuf:"bytes,3,opt,name=interval,proto3" json:"interval,omitempty"`
+	// 问候语的语言，规则同 HelloRequest.locale
+	Locale        string `protobuf:"bytes,4,opt,name=locale,proto3" json:"locale,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HelloStreamRequest) Reset() {
+	*x = HelloStreamRequest{}
+	mi := &file_proto_greet_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HelloStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HelloStreamRequest) ProtoMessage() {}
+
+func (x *HelloStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_greet_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HelloStreamRequest.ProtoReflect.Descriptor instead.
+func (*HelloStreamRequest) Descriptor() ([]byte, []int) {
+	return file_proto_greet_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *HelloStreamRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HelloStreamRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *HelloStreamRequest) GetInterval() *durationpb.Duration {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+func (x *HelloStreamRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+// ManyHelloReply 多人问候的汇总回复
+type ManyHelloReply struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 汇总的问候语
+	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// 按接收顺序排列的名字
+	Names []string `protobuf:"bytes,2,rep,name=names,proto3" json:"names,omitempty"`
+	// 问候语实际使用的语言
+	Locale        string `protobuf:"bytes,3,opt,name=locale,proto3" json:"locale,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ManyHelloReply) Reset() {
+	*x = ManyHelloReply{}
+	mi := &file_proto_greet_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ManyHelloReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ManyHelloReply) ProtoMessage() {}
+
+func (x *ManyHelloReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_greet_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ManyHelloReply.ProtoReflect.Descriptor instead.
+func (*ManyHelloReply) Descriptor() ([]byte, []int) {
+	return file_proto_greet_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ManyHelloReply) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ManyHelloReply) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+func (x *ManyHelloReply) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+// ChatMessage 聊天消息
+type ChatMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 发送者
+	From string `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	// 消息内容
+	Text          string `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatMessage) Reset() {
+	*x = ChatMessage{}
+	mi := &file_proto_greet_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatMessage) ProtoMessage() {}
+
+func (x *ChatMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_greet_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatMessage.ProtoReflect.Descriptor instead.
+func (*ChatMessage) Descriptor() ([]byte, []int) {
+	return file_proto_greet_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ChatMessage) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
 var File_proto_greet_proto protoreflect.FileDescriptor
 
-// 文件的原始描述符字符串
 const file_proto_greet_proto_rawDesc = "" +
 	"\n" +
-	"\x11proto/greet.proto\x12\x05greet\"\"\n" +
+	"\x11proto/greet.proto\x12\x05greet\x1a\x1cgoogle/api/annotations.proto\x1a\x1egoogle/protobuf/duration.proto\":\n" +
 	"\fHelloRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\"&\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06locale\x18\x02 \x01(\tR\x06locale\">\n" +
 	"\n" +
 	"HelloReply\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage2?\n" +
-	"\aGreeter\x124\n" +
-	"\bSayHello\x12\x13.greet.HelloRequest\x1a\x11.greet.HelloReply\"\x00B\tZ\a.;greetb\x06proto3"
+	"\amessage\x18\x01 \x01(\tR\amessage\x12\x16\n" +
+	"\x06locale\x18\x02 \x01(\tR\x06locale\"\x8d\x01\n" +
+	"\x12HelloStreamRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\x125\n" +
+	"\binterval\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\binterval\x12\x16\n" +
+	"\x06locale\x18\x04 \x01(\tR\x06locale\"X\n" +
+	"\x0eManyHelloReply\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12\x14\n" +
+	"\x05names\x18\x02 \x03(\tR\x05names\x12\x16\n" +
+	"\x06locale\x18\x03 \x01(\tR\x06locale\"5\n" +
+	"\vChatMessage\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text2\x8f\x02\n" +
+	"\aGreeter\x12H\n" +
+	"\bSayHello\x12\x13.greet.HelloRequest\x1a\x11.greet.HelloReply\"\x14\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/v1/greet\x12B\n" +
+	"\x0eSayHelloStream\x12\x19.greet.HelloStreamRequest\x1a\x11.greet.HelloReply\"\x000\x01\x12@\n" +
+	"\x0eSayHelloToMany\x12\x13.greet.HelloRequest\x1a\x15.greet.ManyHelloReply\"\x00(\x01\x124\n" +
+	"\x04Chat\x12\x12.greet.ChatMessage\x1a\x12.greet.ChatMessage\"\x00(\x010\x01B\x17Z\x15grpc-demo/proto;protob\x06proto3"
 
 var (
 	file_proto_greet_proto_rawDescOnce sync.Once
 	file_proto_greet_proto_rawDescData []byte
 )
 
-// file_proto_greet_proto_rawDescGZIP 返回压缩后的原始描述符数据
 func file_proto_greet_proto_rawDescGZIP() []byte {
 	file_proto_greet_proto_rawDescOnce.Do(func() {
 		file_proto_greet_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_greet_proto_rawDesc), len(file_proto_greet_proto_rawDesc)))
@@ -150,25 +368,33 @@ func file_proto_greet_proto_rawDescGZIP() []byte {
 	return file_proto_greet_proto_rawDescData
 }
 
-var file_proto_greet_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_greet_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_proto_greet_proto_goTypes = []any{
-	(*HelloRequest)(nil), // 0: greet.HelloRequest
-	(*HelloReply)(nil),   // 1: greet.HelloReply
+	(*HelloRequest)(nil),        // 0: greet.HelloRequest
+	(*HelloReply)(nil),          // 1: greet.HelloReply
+	(*HelloStreamRequest)(nil),  // 2: greet.HelloStreamRequest
+	(*ManyHelloReply)(nil),      // 3: greet.ManyHelloReply
+	(*ChatMessage)(nil),         // 4: greet.ChatMessage
+	(*durationpb.Duration)(nil), // 5: google.protobuf.Duration
 }
 var file_proto_greet_proto_depIdxs = []int32{
-	0, // 0: greet.Greeter.SayHello:input_type -> greet.HelloRequest
-	1, // 1: greet.Greeter.SayHello:output_type -> greet.HelloReply
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	5, // 0: greet.HelloStreamRequest.interval:type_name -> google.protobuf.Duration
+	0, // 1: greet.Greeter.SayHello:input_type -> greet.HelloRequest
+	2, // 2: greet.Greeter.SayHelloStream:input_type -> greet.HelloStreamRequest
+	0, // 3: greet.Greeter.SayHelloToMany:input_type -> greet.HelloRequest
+	4, // 4: greet.Greeter.Chat:input_type -> greet.ChatMessage
+	1, // 5: greet.Greeter.SayHello:output_type -> greet.HelloReply
+	1, // 6: greet.Greeter.SayHelloStream:output_type -> greet.HelloReply
+	3, // 7: greet.Greeter.SayHelloToMany:output_type -> greet.ManyHelloReply
+	4, // 8: greet.Greeter.Chat:output_type -> greet.ChatMessage
+	5, // [5:9] is the sub-list for method output_type
+	1, // [1:5] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
-// init 初始化文件描述符
 func init() { file_proto_greet_proto_init() }
-
-// file_proto_greet_proto_init 初始化 greet.proto 文件的类型信息
 func file_proto_greet_proto_init() {
 	if File_proto_greet_proto != nil {
 		return
@@ -179,7 +405,7 @@ func file_proto_greet_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_greet_proto_rawDesc), len(file_proto_greet_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -1,8 +1,8 @@
-// 由 protoc-gen-go-grpc 生成的代码。不要编辑。
-// 版本信息:
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
 // - protoc-gen-go-grpc v1.5.1
 // - protoc             v5.29.3
-// 源文件: proto/greet.proto
+// source: proto/greet.proto
 
 package proto
 
@@ -13,33 +13,42 @@ import (
 	status "google.golang.org/grpc/status"
 )
 
-// 编译时断言，确保生成的文件与当前 grpc 包兼容。
-// 需要 gRPC-Go v1.64.0 或更高版本。
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	// 定义服务方法的完整 gRPC 路径
-	Greeter_SayHello_FullMethodName = "/greet.Greeter/SayHello"
+	Greeter_SayHello_FullMethodName       = "/greet.Greeter/SayHello"
+	Greeter_SayHelloStream_FullMethodName = "/greet.Greeter/SayHelloStream"
+	Greeter_SayHelloToMany_FullMethodName = "/greet.Greeter/SayHelloToMany"
+	Greeter_Chat_FullMethodName           = "/greet.Greeter/Chat"
 )
 
-// GreeterClient 是 Greeter 服务的客户端接口。
+// GreeterClient is the client API for Greeter service.
 //
-// 有关 ctx 使用和关闭/结束流式 RPC 的语义，请参考 https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream。
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Greeter 问候服务
 type GreeterClient interface {
+	// SayHello 向一个名字发送一条问候，HTTP 网关映射为 POST /v1/greet
 	SayHello(ctx context.Context, in *HelloRequest, opts ...grpc.CallOption) (*HelloReply, error)
+	// SayHelloStream 服务端流式：按 interval 间隔重复发送 count 条问候
+	SayHelloStream(ctx context.Context, in *HelloStreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[HelloReply], error)
+	// SayHelloToMany 客户端流式：接收多个名字，客户端发送结束后返回一条汇总的问候
+	SayHelloToMany(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[HelloRequest, ManyHelloReply], error)
+	// Chat 双向流式：对客户端发送的每条消息回复一条问候
+	Chat(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ChatMessage, ChatMessage], error)
 }
 
-// greeterClient 实现了 GreeterClient 接口
 type greeterClient struct {
 	cc grpc.ClientConnInterface
 }
 
-// NewGreeterClient 创建一个新的 Greeter 客户端实例
 func NewGreeterClient(cc grpc.ClientConnInterface) GreeterClient {
 	return &greeterClient{cc}
 }
 
-// SayHello 实现 GreeterClient 接口的方法
 func (c *greeterClient) SayHello(ctx context.Context, in *HelloRequest, opts ...grpc.CallOption) (*HelloReply, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(HelloReply)
@@ -50,43 +59,108 @@ func (c *greeterClient) SayHello(ctx context.Context, in *HelloRequest, opts ...
 	return out, nil
 }
 
-// GreeterServer 是 Greeter 服务的服务端接口。
-// 所有实现必须嵌入 UnimplementedGreeterServer 以保持向前兼容性。
+func (c *greeterClient) SayHelloStream(ctx context.Context, in *HelloStreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[HelloReply], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Greeter_ServiceDesc.Streams[0], Greeter_SayHelloStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[HelloStreamRequest, HelloReply]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Greeter_SayHelloStreamClient = grpc.ServerStreamingClient[HelloReply]
+
+func (c *greeterClient) SayHelloToMany(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[HelloRequest, ManyHelloReply], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Greeter_ServiceDesc.Streams[1], Greeter_SayHelloToMany_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[HelloRequest, ManyHelloReply]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Greeter_SayHelloToManyClient = grpc.ClientStreamingClient[HelloRequest, ManyHelloReply]
+
+func (c *greeterClient) Chat(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ChatMessage, ChatMessage], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Greeter_ServiceDesc.Streams[2], Greeter_Chat_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ChatMessage, ChatMessage]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Greeter_ChatClient = grpc.BidiStreamingClient[ChatMessage, ChatMessage]
+
+// GreeterServer is the server API for Greeter service.
+// All implementations must embed UnimplementedGreeterServer
+// for forward compatibility.
+//
+// Greeter 问候服务
 type GreeterServer interface {
+	// SayHello 向一个名字发送一条问候，HTTP 网关映射为 POST /v1/greet
 	SayHello(context.Context, *HelloRequest) (*HelloReply, error)
+	// SayHelloStream 服务端流式：按 interval 间隔重复发送 count 条问候
+	SayHelloStream(*HelloStreamRequest, grpc.ServerStreamingServer[HelloReply]) error
+	// SayHelloToMany 客户端流式：接收多个名字，客户端发送结束后返回一条汇总的问候
+	SayHelloToMany(grpc.ClientStreamingServer[HelloRequest, ManyHelloReply]) error
+	// Chat 双向流式：对客户端发送的每条消息回复一条问候
+	Chat(grpc.BidiStreamingServer[ChatMessage, ChatMessage]) error
 	mustEmbedUnimplementedGreeterServer()
 }
 
-// UnimplementedGreeterServer 必须被嵌入以保证实现的向前兼容。
+// UnimplementedGreeterServer must be embedded to have
+// forward compatible implementations.
 //
-// 注意：为了防止方法调用时发生空指针解引用，应该以值类型而非指针类型嵌入。
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
 type UnimplementedGreeterServer struct{}
 
 func (UnimplementedGreeterServer) SayHello(context.Context, *HelloRequest) (*HelloReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SayHello not implemented")
 }
-
+func (UnimplementedGreeterServer) SayHelloStream(*HelloStreamRequest, grpc.ServerStreamingServer[HelloReply]) error {
+	return status.Errorf(codes.Unimplemented, "method SayHelloStream not implemented")
+}
+func (UnimplementedGreeterServer) SayHelloToMany(grpc.ClientStreamingServer[HelloRequest, ManyHelloReply]) error {
+	return status.Errorf(codes.Unimplemented, "method SayHelloToMany not implemented")
+}
+func (UnimplementedGreeterServer) Chat(grpc.BidiStreamingServer[ChatMessage, ChatMessage]) error {
+	return status.Errorf(codes.Unimplemented, "method Chat not implemented")
+}
 func (UnimplementedGreeterServer) mustEmbedUnimplementedGreeterServer() {}
 func (UnimplementedGreeterServer) testEmbeddedByValue()                 {}
 
-// UnsafeGreeterServer 可以被嵌入以放弃此服务的向前兼容性。
-// 不建议使用此接口，因为向 GreeterServer 添加方法将导致编译错误。
+// UnsafeGreeterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GreeterServer will
+// result in compilation errors.
 type UnsafeGreeterServer interface {
 	mustEmbedUnimplementedGreeterServer()
 }
 
-// RegisterGreeterServer 注册 GreeterServer 到 gRPC 服务注册器中
 func RegisterGreeterServer(s grpc.ServiceRegistrar, srv GreeterServer) {
-	// 如果下面的调用 panic，说明 UnimplementedGreeterServer 是以指针形式嵌入且为 nil。
-	// 这会导致在运行时调用未实现的方法时发生 panic，
-	// 因此我们在初始化时进行检查以避免这种情况。
+	// If the following call pancis, it indicates UnimplementedGreeterServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
 	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
 		t.testEmbeddedByValue()
 	}
 	s.RegisterService(&Greeter_ServiceDesc, srv)
 }
 
-// _Greeter_SayHello_Handler 是 SayHello 方法的 gRPC 处理程序
 func _Greeter_SayHello_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HelloRequest)
 	if err := dec(in); err != nil {
@@ -105,9 +179,34 @@ func _Greeter_SayHello_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
-// Greeter_ServiceDesc 是 Greeter 服务的 gRPC 服务描述符。
-// 主要用于直接与 grpc.RegisterService 配合使用，
-// 不应被内省或修改（即使是副本）
+func _Greeter_SayHelloStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(HelloStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GreeterServer).SayHelloStream(m, &grpc.GenericServerStream[HelloStreamRequest, HelloReply]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Greeter_SayHelloStreamServer = grpc.ServerStreamingServer[HelloReply]
+
+func _Greeter_SayHelloToMany_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GreeterServer).SayHelloToMany(&grpc.GenericServerStream[HelloRequest, ManyHelloReply]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Greeter_SayHelloToManyServer = grpc.ClientStreamingServer[HelloRequest, ManyHelloReply]
+
+func _Greeter_Chat_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GreeterServer).Chat(&grpc.GenericServerStream[ChatMessage, ChatMessage]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Greeter_ChatServer = grpc.BidiStreamingServer[ChatMessage, ChatMessage]
+
+// Greeter_ServiceDesc is the grpc.ServiceDesc for Greeter service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
 var Greeter_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "greet.Greeter",
 	HandlerType: (*GreeterServer)(nil),
@@ -117,6 +216,23 @@ var Greeter_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _Greeter_SayHello_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SayHelloStream",
+			Handler:       _Greeter_SayHelloStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SayHelloToMany",
+			Handler:       _Greeter_SayHelloToMany_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Chat",
+			Handler:       _Greeter_Chat_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "proto/greet.proto",
 }
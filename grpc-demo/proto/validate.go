@@ -0,0 +1,90 @@
+package proto
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// 本文件为请求消息实现 interceptor.Validator，由服务端的校验拦截器调用。
+// 通过网络收到的 proto3 字符串已经在解码时检查过 UTF-8，这里的检查用于进程内直接调用的情况。
+
+const (
+	// MaxNameLength 名字的最大字符数
+	MaxNameLength = 64
+	// MaxTextLength 聊天消息的最大字符数
+	MaxTextLength = 1024
+	// MaxLocaleLength locale 的最大长度，足够容纳带权重的多个语言
+	MaxLocaleLength = 128
+)
+
+var (
+	// languageTag BCP 47 语言标签的形式：1-8 个字母的主标签，后跟若干 1-8 个字母或数字的子标签；* 表示任意语言
+	languageTag = regexp.MustCompile(`^(\*|[A-Za-z]{1,8}(-[A-Za-z0-9]{1,8})*)$`)
+	// qualityValue Accept-Language 中的权重，0 到 1 之间最多 3 位小数
+	qualityValue = regexp.MustCompile(`^(0(\.[0-9]{0,3})?|1(\.0{0,3})?)$`)
+)
+
+// checkString 检查字符串非空、是合法的 UTF-8 且不超过 max 个字符
+func checkString(field, value string, max int) []*errdetails.BadRequest_FieldViolation {
+	violation := func(desc string) []*errdetails.BadRequest_FieldViolation {
+		return []*errdetails.BadRequest_FieldViolation{{Field: field, Description: desc}}
+	}
+	switch {
+	case value == "":
+		return violation("不能为空")
+	case !utf8.ValidString(value):
+		return violation("不是合法的 UTF-8 字符串")
+	case utf8.RuneCountInString(value) > max:
+		return violation(fmt.Sprintf("长度不能超过 %d 个字符", max))
+	}
+	return nil
+}
+
+// checkLocale 检查 Accept-Language 格式的 locale：可以为空，不超过 MaxLocaleLength 个字节，
+// 逗号分隔的每一项都是语言标签，可以带 ;q= 权重。语言是否受支持由服务端协商，这里只检查形式
+func checkLocale(field, value string) []*errdetails.BadRequest_FieldViolation {
+	violation := func(desc string) []*errdetails.BadRequest_FieldViolation {
+		return []*errdetails.BadRequest_FieldViolation{{Field: field, Description: desc}}
+	}
+	if value == "" {
+		return nil
+	}
+	if len(value) > MaxLocaleLength {
+		return violation(fmt.Sprintf("长度不能超过 %d 个字节", MaxLocaleLength))
+	}
+	for item := range strings.SplitSeq(value, ",") {
+		tag, q, hasQ := strings.Cut(strings.TrimSpace(item), ";")
+		if !languageTag.MatchString(strings.TrimSpace(tag)) {
+			return violation(fmt.Sprintf("%q 不是合法的语言标签", strings.TrimSpace(tag)))
+		}
+		if hasQ {
+			w, ok := strings.CutPrefix(strings.TrimSpace(q), "q=")
+			if !ok || !qualityValue.MatchString(w) {
+				return violation(fmt.Sprintf("%q 不是合法的权重", strings.TrimSpace(q)))
+			}
+		}
+	}
+	return nil
+}
+
+// Validate 校验 HelloRequest
+func (x *HelloRequest) Validate() []*errdetails.BadRequest_FieldViolation {
+	return append(checkString("name", x.GetName(), MaxNameLength),
+		checkLocale("locale", x.GetLocale())...)
+}
+
+// Validate 校验 HelloStreamRequest，count 和 interval 的范围由服务端检查
+func (x *HelloStreamRequest) Validate() []*errdetails.BadRequest_FieldViolation {
+	return append(checkString("name", x.GetName(), MaxNameLength),
+		checkLocale("locale", x.GetLocale())...)
+}
+
+// Validate 校验 ChatMessage
+func (x *ChatMessage) Validate() []*errdetails.BadRequest_FieldViolation {
+	return append(checkString("from", x.GetFrom(), MaxNameLength),
+		checkString("text", x.GetText(), MaxTextLength)...)
+}
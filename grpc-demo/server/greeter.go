@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"example.com/greetings"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	pb "grpc-demo/proto" // 模块路径
+)
+
+const (
+	// defaultStreamCount SayHelloStream 未指定次数时发送的问候条数
+	defaultStreamCount = 3
+	// maxStreamCount SayHelloStream 允许的最大问候条数，防止客户端占用连接过久
+	maxStreamCount = 100
+)
+
+// server 结构体实现了 pb.UnimplementedGreeterServer 接口，用于处理 gRPC 请求。
+type server struct {
+	pb.UnimplementedGreeterServer
+	// greetings 按语言生成问候语
+	greetings *greetings.Localizer
+}
+
+// acceptLanguage 确定问候语的语言偏好：优先使用请求中的 locale 字段，
+// 其次是元数据中的 accept-language（HTTP 网关转发时带 grpcgateway- 前缀），
+// 都没有时返回空字符串，由 Localizer 使用默认语言
+func acceptLanguage(ctx context.Context, locale string) string {
+	if locale != "" {
+		return locale
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, key := range []string{"accept-language", "grpcgateway-accept-language"} {
+		if v := md.Get(key); len(v) > 0 {
+			return strings.Join(v, ",")
+		}
+	}
+	return ""
+}
+
+// greetingError 把生成问候语的错误转换为 gRPC 状态
+func greetingError(err error) error {
+	if errors.Is(err, greetings.ErrEmptyName) {
+		return status.Error(codes.InvalidArgument, "名字不能为空")
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// SayHello 方法处理来自客户端的 HelloRequest 请求。
+// 该方法接收一个上下文和一个 HelloRequest 对象，返回一个 HelloReply 对象和一个错误对象。
+// 主要功能是按请求的语言构造回复消息，如中文的 "你好, [请求的名字]!"。
+func (s *server) SayHello(ctx context.Context, req *pb.HelloRequest) (*pb.HelloReply, error) {
+	// 记录收到的请求名字，调用的方法、状态码和耗时由日志拦截器记录
+	slog.InfoContext(ctx, "收到请求", "name", req.GetName(), "locale", req.GetLocale())
+	g, err := s.greetings.Hello(acceptLanguage(ctx, req.GetLocale()), req.GetName())
+	if err != nil {
+		return nil, greetingError(err)
+	}
+	// 构造回复消息并返回
+	return &pb.HelloReply{Message: g.Message, Locale: g.Locale}, nil
+}
+
+// SayHelloStream 按请求的间隔重复发送问候，客户端取消或超时时提前结束
+func (s *server) SayHelloStream(req *pb.HelloStreamRequest, stream grpc.ServerStreamingServer[pb.HelloReply]) error {
+	count := int(req.GetCount())
+	switch {
+	case count < 0 || count > maxStreamCount:
+		return status.Errorf(codes.InvalidArgument, "count 需要在 0 到 %d 之间", maxStreamCount)
+	case count == 0:
+		count = defaultStreamCount
+	}
+	interval := req.GetInterval().AsDuration()
+	if req.GetInterval() != nil && (req.GetInterval().CheckValid() != nil || interval < 0) {
+		return status.Error(codes.InvalidArgument, "interval 不合法")
+	}
+	ctx := stream.Context()
+	slog.InfoContext(ctx, "收到流式请求", "name", req.GetName(), "count", count, "interval", interval)
+	accept := acceptLanguage(ctx, req.GetLocale())
+
+	for i := 1; i <= count; i++ {
+		if i > 1 && interval > 0 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return status.FromContextError(ctx.Err()).Err()
+			case <-timer.C:
+			}
+		}
+		g, err := s.greetings.Hello(accept, req.GetName())
+		if err != nil {
+			return greetingError(err)
+		}
+		msg := fmt.Sprintf("%s (%d/%d)", g.Message, i, count)
+		if err := stream.Send(&pb.HelloReply{Message: msg, Locale: g.Locale}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SayHelloToMany 接收客户端发送的全部名字，客户端关闭发送后返回一条汇总的问候
+// 语言取第一个带 locale 的请求，都没有时使用元数据
+func (s *server) SayHelloToMany(stream grpc.ClientStreamingServer[pb.HelloRequest, pb.ManyHelloReply]) error {
+	var names []string
+	var locale string
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		names = append(names, req.GetName())
+		if locale == "" {
+			locale = req.GetLocale()
+		}
+	}
+	slog.InfoContext(stream.Context(), "收到多个名字", "names", names)
+	if len(names) == 0 {
+		return status.Error(codes.InvalidArgument, "至少需要一个名字")
+	}
+	g, err := s.greetings.HelloAll(acceptLanguage(stream.Context(), locale), names)
+	if err != nil {
+		return greetingError(err)
+	}
+	return stream.SendAndClose(&pb.ManyHelloReply{Message: g.Message, Names: names, Locale: g.Locale})
+}
+
+// Chat 对客户端发送的每条消息回复一条问候，客户端关闭发送后结束
+// ChatMessage 没有 locale 字段，语言由元数据中的 accept-language 决定
+func (s *server) Chat(stream grpc.BidiStreamingServer[pb.ChatMessage, pb.ChatMessage]) error {
+	accept := acceptLanguage(stream.Context(), "")
+	for {
+		in, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		slog.InfoContext(stream.Context(), "收到聊天消息", "from", in.GetFrom(), "text", in.GetText())
+		g, err := s.greetings.Reply(accept, in.GetFrom(), in.GetText())
+		if err != nil {
+			return greetingError(err)
+		}
+		if err := stream.Send(&pb.ChatMessage{From: "服务端", Text: g.Message}); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"example.com/greetings"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+	pb "grpc-demo/proto"
+)
+
+// newTestLocalizer 返回中文和英文各只有一种格式的 Localizer，问候语是确定的，默认语言为中文
+func newTestLocalizer(t *testing.T) *greetings.Localizer {
+	t.Helper()
+	l, err := greetings.NewLocalizer(
+		greetings.WithFallback("zh"),
+		greetings.WithCatalog("zh", greetings.Catalog{Formats: []string{"你好, %v!"}, Separator: "、", Reply: "你说: %v"}),
+		greetings.WithCatalog("en", greetings.Catalog{Formats: []string{"Hello, %v!"}, Separator: ", ", Reply: "You said: %v"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// newTestClient 在内存连接上启动 Greeter 服务，返回连接到该服务的客户端
+func newTestClient(t *testing.T) pb.GreeterClient {
+	t.Helper()
+	s := grpc.NewServer()
+	pb.RegisterGreeterServer(s, &server{greetings: newTestLocalizer(t)})
+	return pb.NewGreeterClient(serveBufconn(t, s))
+}
+
+// serveBufconn 在内存连接上启动 s，返回连接到 s 的客户端连接；测试结束时停止 s
+func serveBufconn(t *testing.T, s *grpc.Server) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestSayHello(t *testing.T) {
+	client := newTestClient(t)
+	res, err := client.SayHello(t.Context(), &pb.HelloRequest{Name: "张三"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "你好, 张三!"; res.GetMessage() != want {
+		t.Errorf("got %q, want %q", res.GetMessage(), want)
+	}
+}
+
+func TestSayHelloLocale(t *testing.T) {
+	client := newTestClient(t)
+	tests := []struct {
+		name    string
+		locale  string
+		md      []string
+		want    string
+		wantLoc string
+	}{
+		{"请求字段", "en", nil, "Hello, 张三!", "en"},
+		{"地区回退到语言", "en-GB", nil, "Hello, 张三!", "en"},
+		{"不支持的语言使用默认语言", "fr", nil, "你好, 张三!", "zh"},
+		{"元数据", "", []string{"accept-language", "fr;q=0.9, en;q=0.5"}, "Hello, 张三!", "en"},
+		{"网关转发的请求头", "", []string{"grpcgateway-accept-language", "en-US"}, "Hello, 张三!", "en"},
+		{"请求字段优先于元数据", "zh-CN", []string{"accept-language", "en"}, "你好, 张三!", "zh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.AppendToOutgoingContext(t.Context(), tt.md...)
+			res, err := client.SayHello(ctx, &pb.HelloRequest{Name: "张三", Locale: tt.locale})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.GetMessage() != tt.want || res.GetLocale() != tt.wantLoc {
+				t.Errorf("got %q (%s), want %q (%s)", res.GetMessage(), res.GetLocale(), tt.want, tt.wantLoc)
+			}
+		})
+	}
+
+	// 没有经过校验拦截器时，空名字由 Localizer 拒绝
+	if _, err := client.SayHello(t.Context(), &pb.HelloRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("空名字 err = %v, want InvalidArgument", err)
+	}
+}
+
+func TestSayHelloStream(t *testing.T) {
+	client := newTestClient(t)
+
+	recvAll := func(req *pb.HelloStreamRequest) ([]string, error) {
+		stream, err := client.SayHelloStream(t.Context(), req)
+		if err != nil {
+			return nil, err
+		}
+		var got []string
+		for {
+			res, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return got, nil
+			}
+			if err != nil {
+				return got, err
+			}
+			got = append(got, res.GetMessage())
+		}
+	}
+
+	start := time.Now()
+	got, err := recvAll(&pb.HelloStreamRequest{Name: "张三", Count: 3, Interval: durationpb.New(10 * time.Millisecond)})
+	want := []string{"你好, 张三! (1/3)", "你好, 张三! (2/3)", "你好, 张三! (3/3)"}
+	if err != nil || !slices.Equal(got, want) {
+		t.Errorf("got %q, %v, want %q", got, err, want)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("3 条问候间隔 10ms，耗时 %v 过短", elapsed)
+	}
+
+	if got, err := recvAll(&pb.HelloStreamRequest{Name: "李四"}); err != nil || len(got) != defaultStreamCount {
+		t.Errorf("默认次数: got %q, %v", got, err)
+	}
+
+	for _, bad := range []*pb.HelloStreamRequest{
+		{Name: "x", Count: -1},
+		{Name: "x", Count: maxStreamCount + 1},
+		{Name: "x", Interval: durationpb.New(-time.Second)},
+	} {
+		if _, err := recvAll(bad); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%v: err = %v, want InvalidArgument", bad, err)
+		}
+	}
+}
+
+func TestSayHelloStreamCanceled(t *testing.T) {
+	client := newTestClient(t)
+	ctx, cancel := context.WithCancel(t.Context())
+	stream, err := client.SayHelloStream(ctx, &pb.HelloStreamRequest{Name: "张三", Count: 10, Interval: durationpb.New(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	// 第二条问候需要等待一小时，取消后应当立即结束
+	cancel()
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Errorf("err = %v, want Canceled", err)
+	}
+}
+
+func TestSayHelloToMany(t *testing.T) {
+	client := newTestClient(t)
+	stream, err := client.SayHelloToMany(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"张三", "李四", "王五"} {
+		if err := stream.Send(&pb.HelloRequest{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "你好, 张三、李四、王五!"; res.GetMessage() != want || res.GetLocale() != "zh" {
+		t.Errorf("got %q (%s), want %q", res.GetMessage(), res.GetLocale(), want)
+	}
+	if !slices.Equal(res.GetNames(), []string{"张三", "李四", "王五"}) {
+		t.Errorf("names = %q", res.GetNames())
+	}
+
+	// 语言取第一个带 locale 的请求
+	en, err := client.SayHelloToMany(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	en.Send(&pb.HelloRequest{Name: "Alice"})
+	en.Send(&pb.HelloRequest{Name: "Bob", Locale: "en"})
+	if res, err := en.CloseAndRecv(); err != nil || res.GetMessage() != "Hello, Alice, Bob!" {
+		t.Errorf("got %v, %v", res, err)
+	}
+
+	empty, err := client.SayHelloToMany(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := empty.CloseAndRecv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("没有名字时 err = %v, want InvalidArgument", err)
+	}
+}
+
+func TestChat(t *testing.T) {
+	client := newTestClient(t)
+	stream, err := client.Chat(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 逐条发送并等待回复，确认服务端不需要等待客户端关闭发送就会回复
+	for _, text := range []string{"在吗", "吃了吗"} {
+		if err := stream.Send(&pb.ChatMessage{From: "张三", Text: text}); err != nil {
+			t.Fatal(err)
+		}
+		reply, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "你好, 张三! 你说: " + text; reply.GetFrom() != "服务端" || reply.GetText() != want {
+			t.Errorf("got %v, want %q", reply, want)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Errorf("关闭发送后 err = %v, want io.EOF", err)
+	}
+
+	// 聊天的语言由元数据决定
+	en, err := client.Chat(metadata.AppendToOutgoingContext(t.Context(), "accept-language", "en"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := en.Send(&pb.ChatMessage{From: "Alice", Text: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if reply, err := en.Recv(); err != nil || reply.GetText() != "Hello, Alice! You said: hi" {
+		t.Errorf("got %v, %v", reply, err)
+	}
+	en.CloseSend()
+}
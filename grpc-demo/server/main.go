@@ -2,46 +2,168 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"io/fs"
 	"log"
+	"log/slog"
 	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"example.com/greetings"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+	"grpc-demo/interceptor"
 	pb "grpc-demo/proto" // 模块路径
 )
 
-// server 结构体实现了 pb.UnimplementedGreeterServer 接口，用于处理 gRPC 请求。
-type server struct {
-	pb.UnimplementedGreeterServer
-}
+func main() {
+	addr := flag.String("addr", ":50051", "监听地址，TCP 地址如 :50051，Unix socket 使用 unix:///tmp/greeter.sock")
+	enableReflection := flag.Bool("reflection", false, "注册服务反射，便于 grpcurl 等工具调试")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 10*time.Second, "收到退出信号后等待进行中请求完成的最长时间，超时后强制停止")
+	metricsAddr := flag.String("metrics-addr", ":9090", "Prometheus /metrics 的 HTTP 监听地址，为空时不提供")
+	traceExporter := flag.String("trace-exporter", "none", "链路追踪导出方式: stdout、otlp、none")
+	otlpEndpoint := flag.String("otlp-endpoint", "localhost:4317", "trace-exporter 为 otlp 时 collector 的地址")
+	defaultLocale := flag.String("default-locale", "zh", "请求没有指定语言或指定的语言不受支持时使用的语言")
+	seed := flag.Uint64("seed", 0, "问候语格式的随机种子，非 0 时每次启动的问候语顺序相同")
+	flag.Parse()
 
-// SayHello 方法处理来自客户端的 HelloRequest 请求。
-// 该方法接收一个上下文和一个 HelloRequest 对象，返回一个 HelloReply 对象和一个错误对象。
-// 主要功能是构造回复消息，消息内容为 "你好, [请求的名字]!"。
-func (s *server) SayHello(ctx context.Context, req *pb.HelloRequest) (*pb.HelloReply, error) {
-	// 记录收到的请求名字
-	log.Printf("收到请求: %v", req.GetName())
-	// 构造回复消息并返回
-	return &pb.HelloReply{Message: "你好, " + req.GetName() + "!"}, nil
-}
+	// 标准库 log 的输出也经过 slog，与拦截器的日志格式一致
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	slog.SetDefault(logger)
 
-func main() {
-	// 监听 TCP 端口 50051
-	lis, err := net.Listen("tcp", ":50051")
+	tp, err := interceptor.NewTracerProvider(context.Background(), "greeter-server", *traceExporter, *otlpEndpoint)
+	if err != nil {
+		log.Fatalf("初始化链路追踪失败: %v", err)
+	}
+	// 退出前导出剩余的 span
+	defer tp.Shutdown(context.Background())
+
+	cfg := interceptor.Config{Logger: logger, TracerProvider: tp, Validation: true}
+	if *metricsAddr != "" {
+		cfg.Metrics = interceptor.NewMetrics()
+		go func() {
+			log.Printf("指标服务运行中: http://%s/metrics", *metricsAddr)
+			if err := cfg.Metrics.ListenAndServe(*metricsAddr); err != nil {
+				log.Printf("指标服务失败: %v", err)
+			}
+		}()
+	}
+
+	lis, err := listen(*addr)
 	if err != nil {
 		// 如果监听失败，记录错误并退出
 		log.Fatalf("监听失败: %v", err)
 	}
 
-	// 创建一个新的 gRPC 服务器实例
-	s := grpc.NewServer()
-	// 将 server 实例注册到 gRPC 服务器中
-	pb.RegisterGreeterServer(s, &server{})
+	opts := []greetings.Option{greetings.WithFallback(*defaultLocale)}
+	if *seed != 0 {
+		opts = append(opts, greetings.WithSeed(*seed))
+	}
+	localizer, err := greetings.NewLocalizer(opts...)
+	if err != nil {
+		log.Fatalf("加载问候语失败: %v", err)
+	}
+	log.Printf("支持的语言: %s，默认语言: %s", strings.Join(localizer.Locales(), ", "), *defaultLocale)
+
+	// 默认策略要求客户端 ping 间隔不小于 5 分钟，否则以 too_many_pings 断开连接；
+	// 这里放宽到 10 秒（客户端允许的最小间隔），并允许没有进行中调用时 ping
+	serverOpts := append(interceptor.ServerOptions(cfg), grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+		MinTime:             10 * time.Second,
+		PermitWithoutStream: true,
+	}))
+	s, hs := newServer(*enableReflection, localizer, serverOpts...)
+
+	// 收到 SIGINT/SIGTERM 时优雅停止。Serve 在监听器关闭后立即返回，
+	// 需要等待 stopped 关闭，确认进行中的请求已经处理完毕
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		log.Printf("收到信号 %v，开始停止服务", <-sig)
+		if !shutdown(s, hs, *shutdownTimeout) {
+			log.Printf("等待 %v 后仍有请求未完成，已强制停止", *shutdownTimeout)
+		}
+	}()
 
 	// 记录服务启动信息
-	log.Println("服务端运行中: 50051 端口")
+	log.Printf("服务端运行中: %s", lis.Addr())
 	// 使用监听器启动 gRPC 服务
 	if err := s.Serve(lis); err != nil {
 		// 如果服务启动失败，记录错误并退出
 		log.Fatalf("服务失败: %v", err)
 	}
+	<-stopped
+	log.Println("服务已停止")
+}
+
+// newServer 创建注册了 Greeter 和健康检查服务的 gRPC 服务器，Greeter 使用 localizer 生成问候语，opts 用于安装拦截器等
+// 健康检查对整体（服务名为空）和每个业务服务分别设置状态
+func newServer(enableReflection bool, localizer *greetings.Localizer, opts ...grpc.ServerOption) (*grpc.Server, *health.Server) {
+	// 创建一个新的 gRPC 服务器实例
+	s := grpc.NewServer(opts...)
+	// 将 server 实例注册到 gRPC 服务器中
+	pb.RegisterGreeterServer(s, &server{greetings: localizer})
+
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(s, hs)
+	hs.SetServingStatus(pb.Greeter_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	if enableReflection {
+		reflection.Register(s)
+	}
+	return s, hs
+}
+
+// listen 监听 addr：unix:///path 或 unix:path 形式的地址监听 Unix socket，其他地址监听 TCP
+// Unix socket 文件已存在时（通常是上次异常退出遗留的）先删除
+func listen(addr string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, "unix://")
+	if !ok {
+		path, ok = strings.CutPrefix(addr, "unix:")
+	}
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&fs.ModeSocket == 0 {
+			return nil, errors.New(path + " 已存在且不是 Unix socket")
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// shutdown 先把健康状态置为 NOT_SERVING，让负载均衡不再分配新请求，然后优雅停止服务器：
+// 不再接受新连接，等待进行中的请求完成。超过 timeout 仍未完成时强制停止并返回 false
+func shutdown(s *grpc.Server, hs *health.Server, timeout time.Duration) bool {
+	hs.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		// Stop 会关闭全部连接，GracefulStop 随之返回
+		s.Stop()
+		<-done
+		return false
+	}
 }
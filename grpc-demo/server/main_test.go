@@ -0,0 +1,146 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	pb "grpc-demo/proto"
+)
+
+func TestHealth(t *testing.T) {
+	s, hs := newServer(false, newTestLocalizer(t))
+	health := healthpb.NewHealthClient(serveBufconn(t, s))
+
+	check := func(service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+		res, err := health.Check(t.Context(), &healthpb.HealthCheckRequest{Service: service})
+		return res.GetStatus(), err
+	}
+	for _, service := range []string{"", "greet.Greeter"} {
+		if st, err := check(service); err != nil || st != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("Check(%q) = %v, %v, want SERVING", service, st, err)
+		}
+	}
+	if _, err := check("unknown.Service"); status.Code(err) != codes.NotFound {
+		t.Errorf("未注册的服务 err = %v, want NotFound", err)
+	}
+
+	hs.Shutdown()
+	if st, _ := check("greet.Greeter"); st != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("停止后 status = %v, want NOT_SERVING", st)
+	}
+}
+
+func TestReflection(t *testing.T) {
+	listServices := func(enable bool) ([]string, error) {
+		s, _ := newServer(enable, newTestLocalizer(t))
+		stream, err := reflectionpb.NewServerReflectionClient(serveBufconn(t, s)).ServerReflectionInfo(t.Context())
+		if err != nil {
+			return nil, err
+		}
+		req := &reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		}
+		if err := stream.Send(req); err != nil {
+			return nil, err
+		}
+		res, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, svc := range res.GetListServicesResponse().GetService() {
+			names = append(names, svc.GetName())
+		}
+		return names, nil
+	}
+
+	names, err := listServices(true)
+	if err != nil || !slices.Contains(names, "greet.Greeter") || !slices.Contains(names, "grpc.health.v1.Health") {
+		t.Errorf("services = %v, %v", names, err)
+	}
+	if _, err := listServices(false); status.Code(err) != codes.Unimplemented {
+		t.Errorf("未开启反射时 err = %v, want Unimplemented", err)
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "greeter.sock")
+	for _, addr := range []string{"unix://" + path, "unix:" + path} {
+		lis, err := listen(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, _ := newServer(false, newTestLocalizer(t))
+		go s.Serve(lis)
+
+		conn, err := grpc.NewClient("unix://"+path, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := pb.NewGreeterClient(conn).SayHello(t.Context(), &pb.HelloRequest{Name: "unix"})
+		if err != nil || res.GetMessage() != "你好, unix!" {
+			t.Errorf("%s: got %q, %v", addr, res.GetMessage(), err)
+		}
+		conn.Close()
+		s.Stop()
+	}
+
+	// 遗留的 socket 文件会被替换，普通文件不会被删除
+	if lis, err := listen("unix:" + path); err != nil {
+		t.Errorf("遗留的 socket 文件: %v", err)
+	} else {
+		lis.Close()
+	}
+	file := filepath.Join(t.TempDir(), "data.txt")
+	if err := os.WriteFile(file, []byte("keep"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listen("unix:" + file); err == nil {
+		t.Error("地址是普通文件时应当返回错误")
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("普通文件被删除: %v", err)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	// 没有进行中的请求时立即优雅停止
+	s, hs := newServer(false, newTestLocalizer(t))
+	serveBufconn(t, s)
+	if !shutdown(s, hs, time.Second) {
+		t.Error("没有进行中的请求时应当优雅停止")
+	}
+
+	// 进行中的流式请求超过等待时间后强制停止
+	s, hs = newServer(false, newTestLocalizer(t))
+	client := pb.NewGreeterClient(serveBufconn(t, s))
+	stream, err := client.SayHelloStream(t.Context(), &pb.HelloStreamRequest{
+		Name: "张三", Count: 2, Interval: durationpb.New(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if shutdown(s, hs, 50*time.Millisecond) {
+		t.Error("有进行中的请求时应当超时后强制停止")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("强制停止耗时 %v", elapsed)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("强制停止后 err = %v, want Unavailable", err)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// chanIterator 旧版基于 goroutine 和无缓冲 channel 的实现，仅作为基准测试的对照组
+type chanIterator[T any] struct {
+	next func() (T, bool)
+}
+
+func newChanIterator[T any](generator func(yield func(T) bool)) *chanIterator[T] {
+	ch := make(chan T)
+	done := make(chan struct{})
+	go func() {
+		defer close(ch)
+		generator(func(v T) bool {
+			select {
+			case ch <- v:
+				return true
+			case <-done:
+				return false
+			}
+		})
+	}()
+	return &chanIterator[T]{
+		next: func() (T, bool) {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					close(done)
+				}
+				return v, ok
+			case <-done:
+				var zero T
+				return zero, false
+			}
+		},
+	}
+}
+
+// chanMap、chanFilter 与旧版一样，每个组合操作再启动一个 goroutine
+func chanMap[T, U any](it *chanIterator[T], f func(T) U) *chanIterator[U] {
+	return newChanIterator(func(yield func(U) bool) {
+		for v, ok := it.next(); ok; v, ok = it.next() {
+			if !yield(f(v)) {
+				return
+			}
+		}
+	})
+}
+
+func chanFilter[T any](it *chanIterator[T], predicate func(T) bool) *chanIterator[T] {
+	return newChanIterator(func(yield func(T) bool) {
+		for v, ok := it.next(); ok; v, ok = it.next() {
+			if predicate(v) && !yield(v) {
+				return
+			}
+		}
+	})
+}
+
+const benchN = 1000
+
+func isEven(v int) bool { return v%2 == 0 }
+func triple(v int) int  { return v * 3 }
+
+// BenchmarkChain 对比 Filter -> Map 链路在两种实现下的耗时和内存分配
+func BenchmarkChain(b *testing.B) {
+	gen := func(yield func(int) bool) {
+		for i := 0; i < benchN; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	b.Run("pull", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			it := Map(NewIterator(gen).Filter(isEven), triple)
+			for _, ok := it.Next(); ok; _, ok = it.Next() {
+			}
+		}
+	})
+	b.Run("closure", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			it := Map(Range(0, benchN-1).Filter(isEven), triple)
+			for _, ok := it.Next(); ok; _, ok = it.Next() {
+			}
+		}
+	})
+	b.Run("channel", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			it := chanMap(chanFilter(newChanIterator(gen), isEven), triple)
+			for _, ok := it.next(); ok; _, ok = it.next() {
+			}
+		}
+	})
+}
+
+// work 模拟 CPU 密集的转换函数
+func work(v int) int {
+	h := v
+	for i := 0; i < 20000; i++ {
+		h = h*31 + i
+	}
+	return h
+}
+
+// sleepy 模拟 IO 密集的转换函数
+func sleepy(v int) int {
+	time.Sleep(100 * time.Microsecond)
+	return v
+}
+
+// BenchmarkParallelMap 对比顺序 Map 与并行 Map 在 CPU 和 IO 密集场景下的耗时
+func BenchmarkParallelMap(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		f    func(int) int
+		n    int
+	}{{"cpu", work, runtime.GOMAXPROCS(0)}, {"io", sleepy, 16}} {
+		b.Run(bc.name+"/sequential", func(b *testing.B) {
+			for b.Loop() {
+				Count(Map(Range(1, 256), bc.f))
+			}
+		})
+		b.Run(bc.name+"/ordered", func(b *testing.B) {
+			for b.Loop() {
+				Count(ParallelMap(Range(1, 256), bc.n, bc.f))
+			}
+		})
+		b.Run(bc.name+"/unordered", func(b *testing.B) {
+			for b.Loop() {
+				Count(ParallelMapUnordered(Range(1, 256), bc.n, bc.f))
+			}
+		})
+	}
+}
+
+// BenchmarkLazyIterator 旧 API 的链式操作：每个元素都需要装箱为 interface{} 并做类型断言
+func BenchmarkLazyIterator(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		it := CreateNumberIterator(1, benchN).(*lazyIterator).
+			Filter(isEvenAny).(*lazyIterator).
+			Map(func(v interface{}) interface{} { return v.(int) * 3 })
+		for _, ok := it.Next(); ok; _, ok = it.Next() {
+		}
+	}
+}
+
+// BenchmarkCombinators 常用组合操作各自处理 benchN 个元素的开销
+func BenchmarkCombinators(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		run  func() int
+	}{
+		{"Skip+Take", func() int { return Count(Range(1, benchN).Skip(10).Take(benchN / 2)) }},
+		{"FlatMap", func() int {
+			return Count(FlatMap(Range(1, benchN/10), func(v int) *Iterator[int] { return Range(1, 10) }))
+		}},
+		{"Chunk", func() int { return Count(Chunk(Range(1, benchN), 16)) }},
+		{"Window", func() int { return Count(Window(Range(1, benchN), 4)) }},
+		{"Distinct", func() int { return Count(Distinct(Map(Range(1, benchN), func(v int) int { return v % 100 }))) }},
+		{"Zip", func() int { return Count(Zip(Range(1, benchN), Range(1, benchN))) }},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				bc.run()
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BufferMetrics 缓冲阶段的运行指标，迭代过程中可以在其他 goroutine 中读取
+type BufferMetrics struct {
+	items           atomic.Int64
+	buffered        func() int
+	maxBuffered     atomic.Int64
+	producerBlocked atomic.Int64
+	consumerBlocked atomic.Int64
+}
+
+// Items 已经交给消费者的元素数量
+func (m *BufferMetrics) Items() int64 { return m.items.Load() }
+
+// Buffered 当前缓冲区中等待消费的元素数量
+func (m *BufferMetrics) Buffered() int { return m.buffered() }
+
+// MaxBuffered 缓冲区中同时等待消费的最大元素数量
+func (m *BufferMetrics) MaxBuffered() int { return int(m.maxBuffered.Load()) }
+
+// ProducerBlocked 上游因缓冲区已满而等待的累计时间，较大说明下游是瓶颈
+func (m *BufferMetrics) ProducerBlocked() time.Duration {
+	return time.Duration(m.producerBlocked.Load())
+}
+
+// ConsumerBlocked 下游因缓冲区为空而等待的累计时间，较大说明上游是瓶颈
+func (m *BufferMetrics) ConsumerBlocked() time.Duration {
+	return time.Duration(m.consumerBlocked.Load())
+}
+
+func (m *BufferMetrics) String() string {
+	return fmt.Sprintf("items=%d buffered=%d max=%d producerBlocked=%v consumerBlocked=%v",
+		m.Items(), m.Buffered(), m.MaxBuffered(), m.ProducerBlocked(), m.ConsumerBlocked())
+}
+
+// observe 记录放入元素后缓冲区中的元素数量
+func (m *BufferMetrics) observe(n int64) {
+	for {
+		old := m.maxBuffered.Load()
+		if n <= old || m.maxBuffered.CompareAndSwap(old, n) {
+			return
+		}
+	}
+}
+
+// Metrics 返回 Buffer/Prefetch 阶段的运行指标，其他阶段返回 nil
+func (it *Iterator[T]) Metrics() *BufferMetrics {
+	return it.metrics
+}
+
+// Buffer 在上游和下游之间加入容量为n的缓冲区：第一次调用 Next 时在独立的 goroutine 中读取上游，
+// 上游最多领先下游n个元素，慢阶段不会让整条链一个元素一个元素地停顿。
+// 上游只会在该 goroutine 中被读取；Stop 先中断阻塞在上游 Next 中的读取（FromChan、NewContextIterator
+// 等支持中断的数据源），再等待该 goroutine 退出后返回。
+func (it *Iterator[T]) Buffer(n int) *Iterator[T] {
+	return newBuffer(it, n, false)
+}
+
+// Prefetch 与 Buffer 相同，但创建后立即开始读取上游，在消费者开始读取前就准备好前n个元素
+func (it *Iterator[T]) Prefetch(n int) *Iterator[T] {
+	return newBuffer(it, n, true)
+}
+
+func newBuffer[T any](up *Iterator[T], n int, eager bool) *Iterator[T] {
+	ch := make(chan T, max(n, 1))
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	metrics := &BufferMetrics{buffered: func() int { return len(ch) }}
+	var upErr error
+	var startOnce, stopOnce, doneOnce sync.Once
+	closeDone := func() { doneOnce.Do(func() { close(done) }) }
+
+	produce := func() {
+		defer close(exited)
+		defer close(ch)
+		defer up.Stop()
+		for {
+			v, ok := up.Next()
+			if !ok {
+				select {
+				case <-done:
+					// 上游是被 Stop 中断的，不是真正的错误
+				default:
+					upErr = up.Err()
+				}
+				return
+			}
+			select {
+			case ch <- v:
+			default:
+				// 缓冲区已满，记录等待时间
+				start := time.Now()
+				select {
+				case ch <- v:
+					metrics.producerBlocked.Add(int64(time.Since(start)))
+				case <-done:
+					return
+				}
+			}
+			metrics.observe(int64(len(ch)))
+		}
+	}
+	start := func() { startOnce.Do(func() { go produce() }) }
+	if eager {
+		start()
+	}
+
+	out := &Iterator[T]{metrics: metrics}
+	out.next = func() (T, bool) {
+		start()
+		var v T
+		var ok bool
+		select {
+		case v, ok = <-ch:
+		default:
+			begin := time.Now()
+			select {
+			case v, ok = <-ch:
+			case <-done:
+				// 作为另一个 Buffer 的上游时被中断
+			}
+			metrics.consumerBlocked.Add(int64(time.Since(begin)))
+		}
+		if ok {
+			metrics.items.Add(1)
+		}
+		return v, ok
+	}
+	out.stop = func() {
+		stopOnce.Do(func() {
+			closeDone()
+			startOnce.Do(func() { close(exited); up.Stop() })
+			// 生产者可能阻塞在上游的 Next 中，先中断上游再等待它退出
+			up.interruptNext()
+			<-exited
+		})
+	}
+	out.interrupt = func() {
+		closeDone()
+		up.interruptNext()
+	}
+	out.err = func() error {
+		// 上游的错误在生产者退出时记录，只有生产者退出后读取才是安全的
+		select {
+		case <-exited:
+			return upErr
+		default:
+			return nil
+		}
+	}
+	return out
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// counted 产生 [0, n) 的数据源，pulled 记录已经被读取的元素数量，可以在其他 goroutine 中读取
+func counted(n int, pulled *atomic.Int64) *Iterator[int] {
+	return newPull(func() (int, bool) {
+		i := int(pulled.Load())
+		if i >= n {
+			return 0, false
+		}
+		pulled.Add(1)
+		return i, true
+	}, nil)
+}
+
+// waitFor 等待条件成立，超时后测试失败
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待超时: %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBufferKeepsOrder(t *testing.T) {
+	checkNoLeaks(t)
+	for _, n := range []int{0, 1, 3, 100} {
+		got := Map(Range(1, 50).Buffer(n), func(v int) int { return v * 2 }).Buffer(n).Collect()
+		want := Map(Range(1, 50), func(v int) int { return v * 2 }).Collect()
+		if !slices.Equal(got, want) {
+			t.Errorf("Buffer(%d): got %v", n, got)
+		}
+	}
+	if got := FromSlice([]int{}).Prefetch(4).Collect(); len(got) != 0 {
+		t.Errorf("空数据源: got %v", got)
+	}
+}
+
+func TestPrefetchRunsAhead(t *testing.T) {
+	checkNoLeaks(t)
+	var pulled atomic.Int64
+	it := counted(100, &pulled).Prefetch(5)
+	defer it.Stop()
+
+	// 缓冲区中5个，另有1个已读取、等待放入缓冲区
+	waitFor(t, "预读5个元素", func() bool { return pulled.Load() == 6 })
+	time.Sleep(10 * time.Millisecond)
+	if got := pulled.Load(); got != 6 {
+		t.Errorf("上游领先了 %d 个元素，应当最多领先 6 个", got)
+	}
+	if m := it.Metrics(); m.Buffered() != 5 || m.MaxBuffered() != 5 || m.Items() != 0 {
+		t.Errorf("metrics = %v", m)
+	}
+
+	if v, ok := it.Next(); !ok || v != 0 {
+		t.Fatalf("Next = %v, %v", v, ok)
+	}
+	waitFor(t, "读取一个后继续预读", func() bool { return pulled.Load() == 7 })
+}
+
+func TestBufferStartsOnFirstNext(t *testing.T) {
+	checkNoLeaks(t)
+	var pulled atomic.Int64
+	it := counted(100, &pulled).Buffer(5)
+	time.Sleep(10 * time.Millisecond)
+	if got := pulled.Load(); got != 0 {
+		t.Errorf("第一次 Next 之前读取了 %d 个元素", got)
+	}
+	if v, ok := it.Next(); !ok || v != 0 {
+		t.Fatalf("Next = %v, %v", v, ok)
+	}
+	it.Stop()
+	if _, ok := it.Next(); ok {
+		t.Error("Stop 之后 Next 仍然返回元素")
+	}
+
+	// 从未读取就停止
+	stopped := false
+	newPull(func() (int, bool) { return 0, true }, func() { stopped = true }).Buffer(3).Stop()
+	if !stopped {
+		t.Error("未启动的 Buffer 停止时没有停止上游")
+	}
+}
+
+func TestBufferStopStopsSource(t *testing.T) {
+	checkNoLeaks(t)
+	var stopped bool
+	it := infinite(&stopped).Filter(func(v int) bool { return v%3 == 0 }).Prefetch(8).Take(4)
+	if got := it.Collect(); !slices.Equal(got, []int{0, 3, 6, 9}) {
+		t.Errorf("got %v", got)
+	}
+	// Stop 等待生产者退出后返回，此时读取 stopped 不存在数据竞争
+	if !stopped {
+		t.Error("Stop 没有传递到数据源")
+	}
+}
+
+func TestBufferErr(t *testing.T) {
+	checkNoLeaks(t)
+	errBoom := errors.New("boom")
+	src := NewContextIterator(t.Context(), func(_ context.Context, yield func(int, error) bool) {
+		if yield(1, nil) && yield(2, nil) {
+			yield(0, errBoom)
+		}
+	})
+	it := src.Buffer(4)
+	got, err := it.CollectErr()
+	if !slices.Equal(got, []int{1, 2}) || !errors.Is(err, errBoom) {
+		t.Errorf("got %v, %v", got, err)
+	}
+}
+
+// stopWithin 在 d 内完成 Stop，否则测试失败（Stop 卡住时不再等待）
+func stopWithin[T any](t *testing.T, it *Iterator[T], d time.Duration) {
+	t.Helper()
+	stopped := make(chan struct{})
+	go func() {
+		it.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(d):
+		t.Fatal("Stop 阻塞在没有数据的上游上")
+	}
+}
+
+func TestBufferStopInterruptsBlockedSource(t *testing.T) {
+	sources := map[string]func() *Iterator[int]{
+		// channel 一直没有数据，ctx 也不会被取消
+		"FromChan": func() *Iterator[int] { return FromChan(context.Background(), make(chan int)) },
+		"Map(FromChan)": func() *Iterator[int] {
+			return Map(FromChan(context.Background(), make(chan int)), func(v int) int { return v * 2 })
+		},
+		"NewContextIterator": func() *Iterator[int] {
+			return NewContextIterator(context.Background(), func(ctx context.Context, yield func(int, error) bool) {
+				<-ctx.Done()
+			})
+		},
+		"Buffer(FromChan)": func() *Iterator[int] {
+			return FromChan(context.Background(), make(chan int)).Prefetch(1)
+		},
+	}
+	for name, source := range sources {
+		t.Run(name, func(t *testing.T) {
+			checkNoLeaks(t)
+			var pulled atomic.Int64
+			it := Concat(counted(1, &pulled), source()).Prefetch(4)
+			waitFor(t, "上游被读取", func() bool { return pulled.Load() == 1 })
+			if v, ok := it.Next(); !ok || v != 0 {
+				t.Fatalf("Next = %d, %v", v, ok)
+			}
+			stopWithin(t, it, time.Second)
+			if err := it.Err(); err != nil {
+				t.Errorf("Stop 中断上游后 Err = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestBufferMetrics(t *testing.T) {
+	checkNoLeaks(t)
+	slow := func(v int) int {
+		time.Sleep(2 * time.Millisecond)
+		return v
+	}
+
+	// 消费者慢：生产者被缓冲区阻塞
+	it := Range(1, 20).Buffer(2)
+	for v := range it.Seq() {
+		slow(v)
+	}
+	m := it.Metrics()
+	if m.Items() != 20 || m.Buffered() != 0 || m.MaxBuffered() > 2 {
+		t.Errorf("慢消费者: %v", m)
+	}
+	if m.ProducerBlocked() == 0 {
+		t.Errorf("慢消费者时 ProducerBlocked 应当大于0: %v", m)
+	}
+
+	// 生产者慢：消费者等待缓冲区
+	it = Map(Range(1, 10), slow).Buffer(4)
+	it.Collect()
+	if m := it.Metrics(); m.Items() != 10 || m.ConsumerBlocked() < 10*time.Millisecond {
+		t.Errorf("慢生产者: %v", m)
+	}
+
+	if Range(1, 3).Metrics() != nil {
+		t.Error("非 Buffer 阶段的 Metrics 应当为 nil")
+	}
+}
+
+func BenchmarkBuffer(b *testing.B) {
+	// 两个各耗时约 50µs 的阶段：不加缓冲时依次执行，加缓冲后在两个 goroutine 中重叠执行
+	work := func(v int) int {
+		time.Sleep(50 * time.Microsecond)
+		return v
+	}
+	pipeline := func(buffer func(*Iterator[int]) *Iterator[int]) int {
+		return Count(Map(buffer(Map(Range(1, 100), work)), work))
+	}
+	b.Run("unbuffered", func(b *testing.B) {
+		for b.Loop() {
+			pipeline(func(it *Iterator[int]) *Iterator[int] { return it })
+		}
+	})
+	b.Run("Buffer(16)", func(b *testing.B) {
+		for b.Loop() {
+			pipeline(func(it *Iterator[int]) *Iterator[int] { return it.Buffer(16) })
+		}
+	})
+}
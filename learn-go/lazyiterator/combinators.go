@@ -0,0 +1,289 @@
+package main
+
+import "errors"
+
+// 本文件提供更多惰性组合操作：只改变读取方式、不改变元素类型的以方法提供，
+// 需要新类型参数或 comparable 约束的以包级函数提供。
+
+// Skip 跳过前n个元素
+func (it *Iterator[T]) Skip(n int) *Iterator[T] {
+	skipped := false
+	return derive(it, func() (T, bool) {
+		if !skipped {
+			skipped = true
+			for i := 0; i < n; i++ {
+				if v, ok := it.Next(); !ok {
+					return v, false
+				}
+			}
+		}
+		return it.Next()
+	})
+}
+
+// SkipWhile 跳过开头满足 predicate 的元素，之后的元素全部保留
+func (it *Iterator[T]) SkipWhile(predicate func(T) bool) *Iterator[T] {
+	skipping := true
+	return derive(it, func() (T, bool) {
+		for {
+			v, ok := it.Next()
+			if !ok || !skipping || !predicate(v) {
+				skipping = false
+				return v, ok
+			}
+		}
+	})
+}
+
+// TakeWhile 取开头满足 predicate 的元素，遇到第一个不满足的元素时结束并停止上游
+func (it *Iterator[T]) TakeWhile(predicate func(T) bool) *Iterator[T] {
+	done := false
+	return derive(it, func() (T, bool) {
+		var zero T
+		if done {
+			return zero, false
+		}
+		v, ok := it.Next()
+		if ok && predicate(v) {
+			return v, true
+		}
+		done = true
+		it.Stop()
+		return zero, false
+	})
+}
+
+// FlatMap 将每个元素映射为一个迭代器，并依次展开其中的元素
+func FlatMap[T, U any](it *Iterator[T], f func(T) *Iterator[U]) *Iterator[U] {
+	var inner *Iterator[U]
+	var innerErr error
+	out := &Iterator[U]{}
+	out.next = func() (U, bool) {
+		for {
+			if inner != nil {
+				if v, ok := inner.Next(); ok {
+					return v, true
+				}
+				if err := inner.Err(); err != nil {
+					innerErr = err
+					out.Stop()
+					var zero U
+					return zero, false
+				}
+				inner = nil
+			}
+			v, ok := it.Next()
+			if !ok {
+				var zero U
+				return zero, false
+			}
+			inner = f(v)
+		}
+	}
+	out.stop = func() {
+		if inner != nil {
+			inner.Stop()
+		}
+		it.Stop()
+	}
+	// inner 只能在消费者 goroutine 中访问，中断只传递到外层的上游
+	out.interrupt = it.interruptNext
+	out.err = func() error {
+		if innerErr != nil {
+			return innerErr
+		}
+		return it.Err()
+	}
+	return out
+}
+
+// Concat 依次连接多个迭代器
+func Concat[T any](its ...*Iterator[T]) *Iterator[T] {
+	i := 0
+	return &Iterator[T]{
+		next: func() (T, bool) {
+			for i < len(its) {
+				if v, ok := its[i].Next(); ok {
+					return v, true
+				}
+				// 当前迭代器出错时不再继续连接后面的迭代器
+				if its[i].Err() != nil {
+					break
+				}
+				i++
+			}
+			var zero T
+			return zero, false
+		},
+		stop: func() {
+			for _, it := range its {
+				it.Stop()
+			}
+		},
+		interrupt: func() {
+			for _, it := range its {
+				it.interruptNext()
+			}
+		},
+		err: func() error {
+			for _, it := range its {
+				if err := it.Err(); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// Zip 将两个迭代器的元素按位置配对，任意一个结束时结束
+func Zip[A, B any](a *Iterator[A], b *Iterator[B]) *Iterator[Pair[A, B]] {
+	return &Iterator[Pair[A, B]]{
+		next: func() (Pair[A, B], bool) {
+			va, ok := a.Next()
+			if !ok {
+				return Pair[A, B]{}, false
+			}
+			vb, ok := b.Next()
+			if !ok {
+				return Pair[A, B]{}, false
+			}
+			return Pair[A, B]{Key: va, Value: vb}, true
+		},
+		stop: func() {
+			a.Stop()
+			b.Stop()
+		},
+		interrupt: func() {
+			a.interruptNext()
+			b.interruptNext()
+		},
+		err: func() error {
+			return errors.Join(a.Err(), b.Err())
+		},
+	}
+}
+
+// Chunk 将元素按每n个一组分块，最后一块可能不足n个；n<=0 时 panic
+func Chunk[T any](it *Iterator[T], n int) *Iterator[[]T] {
+	if n <= 0 {
+		panic("lazyiterator: Chunk size must be positive")
+	}
+	return derive(it, func() ([]T, bool) {
+		chunk := make([]T, 0, n)
+		for len(chunk) < n {
+			v, ok := it.Next()
+			if !ok {
+				break
+			}
+			chunk = append(chunk, v)
+		}
+		return chunk, len(chunk) > 0
+	})
+}
+
+// Window 产生大小为n的滑动窗口，每次向后移动一个元素；元素不足n个时不产生窗口，n<=0 时 panic
+// 每个窗口都是新的切片，可以安全保存
+func Window[T any](it *Iterator[T], n int) *Iterator[[]T] {
+	if n <= 0 {
+		panic("lazyiterator: Window size must be positive")
+	}
+	var window []T
+	return derive(it, func() ([]T, bool) {
+		for len(window) < n {
+			v, ok := it.Next()
+			if !ok {
+				return nil, false
+			}
+			window = append(window, v)
+		}
+		out := append([]T(nil), window...)
+		window = window[1:]
+		return out, true
+	})
+}
+
+// Distinct 去除重复元素，保留第一次出现的元素
+func Distinct[T comparable](it *Iterator[T]) *Iterator[T] {
+	return DistinctBy(it, func(v T) T { return v })
+}
+
+// DistinctBy 按 key 去重，保留 key 第一次出现的元素
+func DistinctBy[T any, K comparable](it *Iterator[T], key func(T) K) *Iterator[T] {
+	seen := make(map[K]struct{})
+	return it.Filter(func(v T) bool {
+		k := key(v)
+		if _, ok := seen[k]; ok {
+			return false
+		}
+		seen[k] = struct{}{}
+		return true
+	})
+}
+
+// Scan 依次累积元素并产生每一步的累积值，例如前缀和
+func Scan[T, U any](it *Iterator[T], init U, f func(acc U, v T) U) *Iterator[U] {
+	acc := init
+	return Map(it, func(v T) U {
+		acc = f(acc, v)
+		return acc
+	})
+}
+
+// Enumerate 为每个元素附带从0开始的下标
+func Enumerate[T any](it *Iterator[T]) *Iterator[Pair[int, T]] {
+	i := -1
+	return Map(it, func(v T) Pair[int, T] {
+		i++
+		return Pair[int, T]{Key: i, Value: v}
+	})
+}
+
+// Reduce 将全部元素累积为一个值
+func Reduce[T, U any](it *Iterator[T], init U, f func(acc U, v T) U) U {
+	acc := init
+	for v := range it.Seq() {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+// Count 统计剩余元素的数量
+func Count[T any](it *Iterator[T]) int {
+	n := 0
+	for range it.Seq() {
+		n++
+	}
+	return n
+}
+
+// Any 是否存在满足 predicate 的元素，找到后立即停止迭代
+func Any[T any](it *Iterator[T], predicate func(T) bool) bool {
+	for v := range it.Seq() {
+		if predicate(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// All 是否所有元素都满足 predicate，空迭代器返回 true；遇到不满足的元素后立即停止迭代
+func All[T any](it *Iterator[T], predicate func(T) bool) bool {
+	return !Any(it, func(v T) bool { return !predicate(v) })
+}
+
+// First 返回第一个元素并停止迭代
+func First[T any](it *Iterator[T]) (T, bool) {
+	defer it.Stop()
+	return it.Next()
+}
+
+// GroupBy 按 key 将全部元素分组，组内保持原有顺序
+func GroupBy[T any, K comparable](it *Iterator[T], key func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	for v := range it.Seq() {
+		k := key(v)
+		groups[k] = append(groups[k], v)
+	}
+	return groups
+}
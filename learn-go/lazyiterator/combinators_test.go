@@ -0,0 +1,176 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+// 以下属性测试用 testing/quick 生成随机切片，将组合操作的结果与直接操作切片的参考实现对比
+
+func quickCheck(t *testing.T, f any) {
+	t.Helper()
+	if err := quick.Check(f, &quick.Config{MaxCount: 200}); err != nil {
+		t.Error(err)
+	}
+}
+
+// size 将随机数限制在 [0, 10) 内，用作 n 参数
+func size(n uint8) int { return int(n % 10) }
+
+func isSmall(v int8) bool { return v < 20 }
+
+func TestSkipTakeProperty(t *testing.T) {
+	quickCheck(t, func(s []int8, n uint8) bool {
+		k := size(n)
+		want := s[min(k, len(s)):]
+		return slices.Equal(FromSlice(s).Skip(k).Collect(), want)
+	})
+	quickCheck(t, func(s []int8, n uint8) bool {
+		// Skip 和 Take 组合等价于切片截取
+		k := size(n)
+		want := s[min(k, len(s)):min(2*k, len(s))]
+		return slices.Equal(FromSlice(s).Skip(k).Take(k).Collect(), want)
+	})
+}
+
+func TestWhileProperty(t *testing.T) {
+	quickCheck(t, func(s []int8) bool {
+		i := slices.IndexFunc(s, func(v int8) bool { return !isSmall(v) })
+		if i < 0 {
+			i = len(s)
+		}
+		return slices.Equal(FromSlice(s).TakeWhile(isSmall).Collect(), s[:i]) &&
+			slices.Equal(FromSlice(s).SkipWhile(isSmall).Collect(), s[i:])
+	})
+}
+
+func TestFlatMapConcatProperty(t *testing.T) {
+	quickCheck(t, func(s [][]int8) bool {
+		var want []int8
+		for _, inner := range s {
+			want = append(want, inner...)
+		}
+		its := make([]*Iterator[int8], len(s))
+		for i, inner := range s {
+			its[i] = FromSlice(inner)
+		}
+		flat := FlatMap(FromSlice(s), func(inner []int8) *Iterator[int8] { return FromSlice(inner) })
+		return slices.Equal(flat.Collect(), want) && slices.Equal(Concat(its...).Collect(), want)
+	})
+}
+
+func TestZipEnumerateProperty(t *testing.T) {
+	quickCheck(t, func(a []int8, b []string) bool {
+		got := Zip(FromSlice(a), FromSlice(b)).Collect()
+		if len(got) != min(len(a), len(b)) {
+			return false
+		}
+		for i, p := range got {
+			if p.Key != a[i] || p.Value != b[i] {
+				return false
+			}
+		}
+		for p := range Enumerate(FromSlice(a)).Seq() {
+			if a[p.Key] != p.Value {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestChunkWindowProperty(t *testing.T) {
+	quickCheck(t, func(s []int8, n uint8) bool {
+		k := size(n) + 1
+		return slices.EqualFunc(Chunk(FromSlice(s), k).Collect(), slices.Collect(slices.Chunk(s, k)), slices.Equal)
+	})
+	quickCheck(t, func(s []int8, n uint8) bool {
+		k := size(n) + 1
+		var want [][]int8
+		for i := 0; i+k <= len(s); i++ {
+			want = append(want, s[i:i+k])
+		}
+		return slices.EqualFunc(Window(FromSlice(s), k).Collect(), want, slices.Equal)
+	})
+}
+
+func TestDistinctProperty(t *testing.T) {
+	quickCheck(t, func(s []int8) bool {
+		seen := map[int8]bool{}
+		var want []int8
+		for _, v := range s {
+			if !seen[v] {
+				seen[v] = true
+				want = append(want, v)
+			}
+		}
+		return slices.Equal(Distinct(FromSlice(s)).Collect(), want)
+	})
+	quickCheck(t, func(s []int8) bool {
+		// 按奇偶去重最多保留两个元素
+		got := DistinctBy(FromSlice(s), func(v int8) bool { return v%2 == 0 }).Collect()
+		return len(got) <= 2 && (len(s) == 0) == (len(got) == 0)
+	})
+}
+
+func TestScanReduceProperty(t *testing.T) {
+	quickCheck(t, func(s []int8) bool {
+		sum := 0
+		var prefix []int
+		for _, v := range s {
+			sum += int(v)
+			prefix = append(prefix, sum)
+		}
+		add := func(acc int, v int8) int { return acc + int(v) }
+		return slices.Equal(Scan(FromSlice(s), 0, add).Collect(), prefix) &&
+			Reduce(FromSlice(s), 0, add) == sum
+	})
+}
+
+func TestTerminalProperty(t *testing.T) {
+	quickCheck(t, func(s []int8) bool {
+		first, ok := First(FromSlice(s))
+		if ok != (len(s) > 0) || (ok && first != s[0]) {
+			return false
+		}
+		return Count(FromSlice(s)) == len(s) &&
+			Any(FromSlice(s), isSmall) == slices.ContainsFunc(s, isSmall) &&
+			All(FromSlice(s), isSmall) == !slices.ContainsFunc(s, func(v int8) bool { return !isSmall(v) })
+	})
+	quickCheck(t, func(s []int8) bool {
+		want := map[bool][]int8{}
+		for _, v := range s {
+			want[v < 0] = append(want[v < 0], v)
+		}
+		return maps.EqualFunc(GroupBy(FromSlice(s), func(v int8) bool { return v < 0 }), want, slices.Equal)
+	})
+}
+
+func TestCombinatorsStopSource(t *testing.T) {
+	checkNoLeaks(t)
+	var stopped bool
+	if v, ok := First(infinite(&stopped).Skip(5)); !ok || v != 5 || !stopped {
+		t.Errorf("First = %v, %v, stopped=%v", v, ok, stopped)
+	}
+
+	stopped = false
+	if !Any(infinite(&stopped), func(v int) bool { return v > 3 }) || !stopped {
+		t.Error("Any 找到元素后没有停止数据源")
+	}
+
+	stopped = false
+	got := Chunk(infinite(&stopped).TakeWhile(func(v int) bool { return v < 5 }), 2).Collect()
+	if !slices.EqualFunc(got, [][]int{{0, 1}, {2, 3}, {4}}, slices.Equal) || !stopped {
+		t.Errorf("got %v, stopped=%v", got, stopped)
+	}
+
+	var a, b bool
+	zipped := Zip(infinite(&a), FlatMap(infinite(&b), func(v int) *Iterator[int] { return Range(0, v) }))
+	zipped.Next()
+	zipped.Stop()
+	if !a || !b {
+		t.Errorf("Zip/FlatMap 停止没有传递到数据源: %v %v", a, b)
+	}
+}
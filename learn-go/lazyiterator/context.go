@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"iter"
+)
+
+// NewContextIterator 创建可取消、可返回错误的惰性迭代器
+// generator: 生成器函数，通过 yield(v, nil) 产生元素，通过 yield(zero, err) 报告错误并结束迭代；
+// yield 返回 false 时（消费者停止、出错或 ctx 被取消）generator 应立即返回。
+// ctx 被取消后 Next 返回 false，Err 返回 ctx.Err()，数据源随即被停止。
+// generator 收到的是 ctx 派生的 context，下游的 Buffer 被 Stop 时会取消它，中断阻塞中的调用。
+func NewContextIterator[T any](ctx context.Context, generator func(ctx context.Context, yield func(T, error) bool)) *Iterator[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	var firstErr error
+	setErr := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	next, stop := iter.Pull(func(yield func(T) bool) {
+		generator(ctx, func(v T, err error) bool {
+			if err != nil {
+				setErr(err)
+				return false
+			}
+			if err := ctx.Err(); err != nil {
+				setErr(err)
+				return false
+			}
+			return yield(v)
+		})
+	})
+
+	it := &Iterator[T]{
+		stop:      func() { stop(); cancel() },
+		err:       func() error { return firstErr },
+		interrupt: cancel,
+	}
+	it.next = func() (T, bool) {
+		// 每次拉取前检查 ctx，取消后不再切换到 generator
+		if err := ctx.Err(); err != nil {
+			setErr(err)
+			it.Stop()
+			var zero T
+			return zero, false
+		}
+		v, ok := next()
+		if !ok && firstErr != nil {
+			it.Stop()
+		}
+		return v, ok
+	}
+	return it
+}
+
+// Paginate 将分页接口包装为惰性迭代器，只有消费到当前页末尾时才请求下一页
+// fetch: 获取第 page 页（从 1 开始）的元素，hasMore 表示是否还有下一页
+func Paginate[T any](ctx context.Context, fetch func(ctx context.Context, page int) (items []T, hasMore bool, err error)) *Iterator[T] {
+	return NewContextIterator(ctx, func(ctx context.Context, yield func(T, error) bool) {
+		for page := 1; ; page++ {
+			items, hasMore, err := fetch(ctx, page)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, v := range items {
+				if !yield(v, nil) {
+					return
+				}
+			}
+			if !hasMore {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestContextIteratorError(t *testing.T) {
+	checkNoLeaks(t)
+	errBoom := errors.New("boom")
+	src := NewContextIterator(context.Background(), func(ctx context.Context, yield func(int, error) bool) {
+		for i := 1; i <= 5; i++ {
+			if !yield(i, nil) {
+				return
+			}
+		}
+		if yield(0, errBoom) {
+			t.Error("报告错误后 yield 应返回 false")
+		}
+	})
+	got, err := Map(src.Filter(func(v int) bool { return v%2 == 1 }), func(v int) string { return fmt.Sprint(v) }).CollectErr()
+	if !errors.Is(err, errBoom) || !slices.Equal(got, []string{"1", "3", "5"}) {
+		t.Errorf("got %v, %v", got, err)
+	}
+}
+
+func TestContextIteratorCancel(t *testing.T) {
+	checkNoLeaks(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan struct{})
+	it := NewContextIterator(ctx, func(ctx context.Context, yield func(int, error) bool) {
+		defer close(stopped)
+		for i := 0; ; i++ {
+			// 模拟耗时的远程调用，ctx 取消后立即返回
+			select {
+			case <-ctx.Done():
+				yield(0, ctx.Err())
+				return
+			case <-time.After(time.Millisecond):
+			}
+			if !yield(i, nil) {
+				return
+			}
+		}
+	}).Take(100)
+
+	if v, ok := it.Next(); !ok || v != 0 {
+		t.Fatalf("Next = %v, %v", v, ok)
+	}
+	cancel()
+	if _, ok := it.Next(); ok {
+		t.Error("取消后 Next 仍然返回元素")
+	}
+	if !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("Err = %v, want context.Canceled", it.Err())
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("取消后数据源没有停止")
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	checkNoLeaks(t)
+	pages := [][]string{{"a.devices", "a.users"}, {"b.devices"}, {"c.users"}}
+	var fetched []int
+	fetch := func(ctx context.Context, page int) ([]string, bool, error) {
+		fetched = append(fetched, page)
+		return pages[page-1], page < len(pages), nil
+	}
+
+	// 只消费前三个元素时不会请求第三页
+	got := Paginate(context.Background(), fetch).Take(3).Collect()
+	if !slices.Equal(got, []string{"a.devices", "a.users", "b.devices"}) || !slices.Equal(fetched, []int{1, 2}) {
+		t.Errorf("got %v, fetched pages %v", got, fetched)
+	}
+
+	errPage := errors.New("page 2 failed")
+	failing := Paginate(context.Background(), func(ctx context.Context, page int) ([]int, bool, error) {
+		if page == 2 {
+			return nil, false, errPage
+		}
+		return []int{page}, true, nil
+	})
+	if got, err := failing.CollectErr(); !errors.Is(err, errPage) || !slices.Equal(got, []int{1}) {
+		t.Errorf("got %v, %v", got, err)
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import "iter"
+
+// Iterator 泛型惰性迭代器
+// 与 LazyIterator 相同按需产生元素，但元素类型在编译期确定，组合操作无需类型断言。
+// 迭代器只是一个拉取函数：Map、Filter、Take 等组合操作直接包装上游的 next 闭包，
+// 链路上不需要额外的 goroutine 和 channel。
+//
+// 消费者提前结束时需要调用 Stop 释放上游：Stop 会沿组合链一直传递到数据源，
+// 结束 generator 所在的协程。迭代器不是并发安全的，Next 和 Stop 需要在同一个 goroutine 中调用。
+type Iterator[T any] struct {
+	next    func() (T, bool)
+	stop    func()
+	err     func() error
+	stopped bool
+	metrics *BufferMetrics // 只有 Buffer/Prefetch 阶段非 nil
+	// interrupt 让阻塞在数据源上的 Next 尽快返回 false，可以在任意 goroutine 中调用，
+	// 供 Buffer 在另一个 goroutine 中停止上游；为 nil 表示数据源不支持中断
+	interrupt func()
+}
+
+// Pair 由两个值组成的元素，用于和 iter.Seq2 互相转换
+type Pair[K, V any] struct {
+	Key   K
+	Value V
+}
+
+// NewIterator 创建新的泛型惰性迭代器
+// generator: 生成器函数，用于产生迭代器元素，当yield返回false时停止生成
+func NewIterator[T any](generator func(yield func(T) bool)) *Iterator[T] {
+	// iter.Pull 基于运行时协程把推模式的 generator 转换为拉模式：
+	// 每次 next 直接切换到 generator 执行到下一个 yield，不经过 channel 同步
+	next, stop := iter.Pull(iter.Seq[T](generator))
+	return &Iterator[T]{next: next, stop: stop}
+}
+
+// newPull 使用拉取函数创建迭代器
+// stop: 停止时调用的函数，可以为 nil
+func newPull[T any](next func() (T, bool), stop func()) *Iterator[T] {
+	return &Iterator[T]{next: next, stop: stop}
+}
+
+// derive 创建包装上游迭代器的组合操作，停止、中断和错误都沿用上游
+func derive[T, U any](up *Iterator[U], next func() (T, bool)) *Iterator[T] {
+	return &Iterator[T]{next: next, stop: up.Stop, err: up.Err, interrupt: up.interruptNext}
+}
+
+// FromSeq 将 iter.Seq 转换为惰性迭代器
+func FromSeq[T any](seq iter.Seq[T]) *Iterator[T] {
+	return NewIterator(seq)
+}
+
+// FromSeq2 将 iter.Seq2 转换为元素为 Pair 的惰性迭代器
+func FromSeq2[K, V any](seq iter.Seq2[K, V]) *Iterator[Pair[K, V]] {
+	return NewIterator(func(yield func(Pair[K, V]) bool) {
+		for k, v := range seq {
+			if !yield(Pair[K, V]{Key: k, Value: v}) {
+				return
+			}
+		}
+	})
+}
+
+// FromSlice 创建按顺序产生切片元素的迭代器
+func FromSlice[T any](s []T) *Iterator[T] {
+	i := 0
+	return newPull(func() (T, bool) {
+		if i >= len(s) {
+			var zero T
+			return zero, false
+		}
+		i++
+		return s[i-1], true
+	}, nil)
+}
+
+// Range 创建包含 [start, end] 范围整数的迭代器
+func Range(start, end int) *Iterator[int] {
+	i := start
+	return newPull(func() (int, bool) {
+		if i > end {
+			return 0, false
+		}
+		i++
+		return i - 1, true
+	}, nil)
+}
+
+// Next 返回迭代器的下一个元素，布尔值表示是否还有元素；Stop 之后始终返回 false
+func (it *Iterator[T]) Next() (T, bool) {
+	if it.stopped {
+		var zero T
+		return zero, false
+	}
+	return it.next()
+}
+
+// Stop 停止迭代并释放上游资源，可以重复调用
+func (it *Iterator[T]) Stop() {
+	if it.stopped {
+		return
+	}
+	it.stopped = true
+	if it.stop != nil {
+		it.stop()
+	}
+}
+
+// interruptNext 中断阻塞在数据源上的 Next，与 Stop 不同，可以在其他 goroutine 中调用。
+// 数据源不支持中断时什么也不做
+func (it *Iterator[T]) interruptNext() {
+	if it.interrupt != nil {
+		it.interrupt()
+	}
+}
+
+// Close 等同于 Stop，便于作为 io.Closer 使用
+func (it *Iterator[T]) Close() error {
+	it.Stop()
+	return nil
+}
+
+// Err 返回迭代过程中遇到的第一个错误；Next 返回 false 后应检查 Err 区分正常结束和出错
+func (it *Iterator[T]) Err() error {
+	if it.err == nil {
+		return nil
+	}
+	return it.err()
+}
+
+// Seq 转换为 iter.Seq，可直接用于 for range 循环
+// 循环结束（包括 break 提前退出）后迭代器会被停止
+func (it *Iterator[T]) Seq() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		defer it.Stop()
+		for v, ok := it.Next(); ok; v, ok = it.Next() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// All 转换为带下标的 iter.Seq2，可用于 for i, v := range it.All()
+// 循环结束后迭代器会被停止
+func (it *Iterator[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		defer it.Stop()
+		i := 0
+		for v, ok := it.Next(); ok; v, ok = it.Next() {
+			if !yield(i, v) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// Pairs 将元素为 Pair 的迭代器转换回 iter.Seq2
+func Pairs[K, V any](it *Iterator[Pair[K, V]]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for p := range it.Seq() {
+			if !yield(p.Key, p.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Map 对迭代器中的每个元素应用函数，元素类型不变；需要转换类型时使用包级函数 Map
+func (it *Iterator[T]) Map(f func(T) T) *Iterator[T] {
+	return Map(it, f)
+}
+
+// Filter 过滤迭代器中的元素
+// predicate: 判断元素是否应该被保留的谓词函数
+func (it *Iterator[T]) Filter(predicate func(T) bool) *Iterator[T] {
+	return derive(it, func() (T, bool) {
+		for {
+			v, ok := it.Next()
+			if !ok || predicate(v) {
+				return v, ok
+			}
+		}
+	})
+}
+
+// Take 取前n个元素
+func (it *Iterator[T]) Take(n int) *Iterator[T] {
+	count := 0
+	return derive(it, func() (T, bool) {
+		// 取够n个元素后停止上游，数据源不会阻塞在下一个 yield 上
+		if count >= n {
+			it.Stop()
+			var zero T
+			return zero, false
+		}
+		count++
+		return it.Next()
+	})
+}
+
+// Collect 读取剩余的全部元素
+func (it *Iterator[T]) Collect() []T {
+	var s []T
+	for v := range it.Seq() {
+		s = append(s, v)
+	}
+	return s
+}
+
+// CollectErr 读取剩余的全部元素，并返回迭代过程中遇到的错误
+func (it *Iterator[T]) CollectErr() ([]T, error) {
+	s := it.Collect()
+	return s, it.Err()
+}
+
+// Map 对迭代器中的每个元素应用函数，可以改变元素类型
+// Go 的方法不能声明额外的类型参数，因此改变类型的 Map 以包级函数提供
+func Map[T, U any](it *Iterator[T], f func(T) U) *Iterator[U] {
+	return derive(it, func() (U, bool) {
+		v, ok := it.Next()
+		if !ok {
+			var zero U
+			return zero, false
+		}
+		return f(v), true
+	})
+}
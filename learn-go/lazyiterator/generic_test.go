@@ -0,0 +1,41 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestIteratorCombinators(t *testing.T) {
+	got := Map(Range(1, 10).Filter(func(v int) bool { return v%2 == 0 }), strconv.Itoa).Take(3).Collect()
+	if want := []string{"2", "4", "6"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestIteratorSeqInterop(t *testing.T) {
+	it := FromSeq(slices.Values([]int{3, 1, 2}))
+	if got := slices.Collect(it.Map(func(v int) int { return v * 10 }).Seq()); !slices.Equal(got, []int{30, 10, 20}) {
+		t.Errorf("Seq = %v", got)
+	}
+
+	m := map[string]int{"a": 1, "b": 2}
+	pairs := FromSeq2(maps.All(m))
+	if got := maps.Collect(Pairs(pairs)); !maps.Equal(got, m) {
+		t.Errorf("Pairs = %v, want %v", got, m)
+	}
+
+	for i, v := range FromSlice([]string{"x", "y"}).All() {
+		if want := []string{"x", "y"}[i]; v != want {
+			t.Errorf("All()[%d] = %q, want %q", i, v, want)
+		}
+	}
+}
+
+func TestLazyIteratorAdapter(t *testing.T) {
+	legacy := ToLazy(Range(1, 5)).(*lazyIterator).Map(func(v interface{}) interface{} { return v.(int) + 1 })
+	if got := FromLazy[int](legacy).Collect(); !slices.Equal(got, []int{2, 3, 4, 5, 6}) {
+		t.Errorf("got %v", got)
+	}
+}
@@ -1,57 +1,314 @@
 package main
 
 import (
-	"fmt"
+	"slices"
+	"sync"
 	"testing"
 )
 
-func Test2(t *testing.T) {
-	fmt.Println("测试惰性迭代器")
+// collectLazy 读取旧 API 迭代器的全部元素
+func collectLazy(it LazyIterator) []interface{} {
+	defer it.Stop()
+	var s []interface{}
+	for v, ok := it.Next(); ok; v, ok = it.Next() {
+		s = append(s, v)
+	}
+	return s
+}
+
+func ints(s ...int) []interface{} {
+	out := make([]interface{}, len(s))
+	for i, v := range s {
+		out[i] = v
+	}
+	return out
+}
 
-	// 创建一个包含1到10数字的惰性迭代器
-	it := NewLazyIterator(func(yield func(interface{}) bool) {
-		for i := 1; i <= 10; i++ {
+func isEvenAny(v interface{}) bool { return v.(int)%2 == 0 }
+
+func TestLazyIterator(t *testing.T) {
+	tests := []struct {
+		name string
+		it   func() LazyIterator
+		want []interface{}
+	}{
+		{"原始数据", func() LazyIterator { return CreateNumberIterator(1, 10) }, ints(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)},
+		{"空区间", func() LazyIterator { return CreateNumberIterator(1, 0) }, nil},
+		{"Map", func() LazyIterator {
+			return CreateNumberIterator(1, 5).(*lazyIterator).Map(func(v interface{}) interface{} { return v.(int) * 2 })
+		}, ints(2, 4, 6, 8, 10)},
+		{"Filter", func() LazyIterator {
+			return CreateNumberIterator(1, 10).(*lazyIterator).Filter(isEvenAny)
+		}, ints(2, 4, 6, 8, 10)},
+		{"Take", func() LazyIterator { return CreateNumberIterator(1, 10).(*lazyIterator).Take(5) }, ints(1, 2, 3, 4, 5)},
+		{"Take(0)", func() LazyIterator { return CreateNumberIterator(1, 10).(*lazyIterator).Take(0) }, nil},
+		{"Take 超过长度", func() LazyIterator { return CreateNumberIterator(1, 3).(*lazyIterator).Take(10) }, ints(1, 2, 3)},
+		{"链式操作", func() LazyIterator {
+			return CreateNumberIterator(1, 10).(*lazyIterator).
+				Filter(isEvenAny).(*lazyIterator).
+				Map(func(v interface{}) interface{} { return v.(int) * 3 }).(*lazyIterator).
+				Take(2)
+		}, ints(6, 12)},
+		{"自定义生成器", func() LazyIterator {
+			return NewLazyIterator(func(yield func(interface{}) bool) {
+				for _, s := range []string{"a", "b"} {
+					if !yield(s) {
+						return
+					}
+				}
+			})
+		}, []interface{}{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkNoLeaks(t)
+			if got := collectLazy(tt.it()); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIteratorChains(t *testing.T) {
+	double := func(v int) int { return v * 2 }
+	isEven := func(v int) bool { return v%2 == 0 }
+	tests := []struct {
+		name string
+		it   func() *Iterator[int]
+		want []int
+	}{
+		{"Range", func() *Iterator[int] { return Range(1, 5) }, []int{1, 2, 3, 4, 5}},
+		{"FromSlice", func() *Iterator[int] { return FromSlice([]int{3, 1, 2}) }, []int{3, 1, 2}},
+		{"Map", func() *Iterator[int] { return Range(1, 4).Map(double) }, []int{2, 4, 6, 8}},
+		{"Filter", func() *Iterator[int] { return Range(1, 6).Filter(isEven) }, []int{2, 4, 6}},
+		{"Take", func() *Iterator[int] { return Range(1, 6).Take(2) }, []int{1, 2}},
+		{"Skip", func() *Iterator[int] { return Range(1, 6).Skip(4) }, []int{5, 6}},
+		{"TakeWhile", func() *Iterator[int] {
+			return FromSlice([]int{1, 2, 5, 1}).TakeWhile(func(v int) bool { return v < 3 })
+		}, []int{1, 2}},
+		{"SkipWhile", func() *Iterator[int] {
+			return FromSlice([]int{1, 2, 5, 1}).SkipWhile(func(v int) bool { return v < 3 })
+		}, []int{5, 1}},
+		{"Concat", func() *Iterator[int] { return Concat(Range(1, 2), Range(1, 0), Range(7, 8)) }, []int{1, 2, 7, 8}},
+		{"FlatMap", func() *Iterator[int] { return FlatMap(Range(1, 3), func(v int) *Iterator[int] { return Range(1, v) }) }, []int{1, 1, 2, 1, 2, 3}},
+		{"Distinct", func() *Iterator[int] { return Distinct(FromSlice([]int{3, 1, 3, 2, 1})) }, []int{3, 1, 2}},
+		{"Scan", func() *Iterator[int] { return Scan(Range(1, 4), 0, func(acc, v int) int { return acc + v }) }, []int{1, 3, 6, 10}},
+		{"链式操作", func() *Iterator[int] { return Range(1, 10).Filter(isEven).Map(double).Skip(1).Take(3) }, []int{8, 12, 16}},
+		{"NewIterator", func() *Iterator[int] {
+			return NewIterator(func(yield func(int) bool) {
+				for i := 10; i > 7; i-- {
+					if !yield(i) {
+						return
+					}
+				}
+			})
+		}, []int{10, 9, 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkNoLeaks(t)
+			if got := tt.it().Collect(); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmptySource(t *testing.T) {
+	checkNoLeaks(t)
+	empty := func() *Iterator[int] { return FromSlice[int](nil) }
+	identity := func(v int) int { return v }
+	always := func(int) bool { return true }
+	for name, it := range map[string]*Iterator[int]{
+		"Map":            empty().Map(identity),
+		"Filter":         empty().Filter(always),
+		"Take":           empty().Take(3),
+		"Skip":           empty().Skip(3),
+		"TakeWhile":      empty().TakeWhile(always),
+		"SkipWhile":      empty().SkipWhile(always),
+		"Concat":         Concat[int](),
+		"FlatMap":        FlatMap(empty(), func(int) *Iterator[int] { return Range(1, 3) }),
+		"Distinct":       Distinct(empty()),
+		"ParallelMap":    ParallelMap(empty(), 4, identity),
+		"Unordered":      ParallelMapUnordered(empty(), 4, identity),
+		"NewIterator":    NewIterator(func(func(int) bool) {}),
+		"Scan":           Scan(empty(), 0, func(acc, v int) int { return acc + v }),
+		"Map(Chunk)":     Map(Chunk(empty(), 2), func(s []int) int { return len(s) }),
+		"Map(Window)":    Map(Window(empty(), 2), func(s []int) int { return len(s) }),
+		"Map(Zip)":       Map(Zip(empty(), Range(1, 3)), func(p Pair[int, int]) int { return p.Key }),
+		"Map(Enumerate)": Map(Enumerate(empty()), func(p Pair[int, int]) int { return p.Key }),
+	} {
+		if got := it.Collect(); len(got) != 0 {
+			t.Errorf("%s: got %v, want empty", name, got)
+		}
+	}
+	if _, ok := First(empty()); ok {
+		t.Error("First on empty returned a value")
+	}
+	if Count(empty()) != 0 || Any(empty(), always) || !All(empty(), func(int) bool { return false }) {
+		t.Error("terminal ops on empty returned wrong results")
+	}
+}
+
+func TestEarlyTermination(t *testing.T) {
+	checkNoLeaks(t)
+	var pulled int
+	src := NewIterator(func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			pulled++
 			if !yield(i) {
 				return
 			}
 		}
 	})
+	for v := range src.Map(func(v int) int { return v * 2 }).Seq() {
+		if v >= 6 {
+			break
+		}
+	}
+	// 读取到第4个元素（6）时退出，数据源不应再被拉取
+	if pulled != 4 {
+		t.Errorf("数据源被拉取了 %d 次，期望 4 次", pulled)
+	}
+	if _, ok := src.Next(); ok {
+		t.Error("循环退出后数据源仍然可以读取")
+	}
+}
 
-	// 测试基本迭代
-	fmt.Println("原始数据:")
-	PrintAll(it)
+// TestConcurrentPipelines 多个 goroutine 各自使用独立的迭代器链，用 -race 运行时不应报告数据竞争
+func TestConcurrentPipelines(t *testing.T) {
+	checkNoLeaks(t)
+	var wg sync.WaitGroup
+	sums := make([]int, 8)
+	for g := range sums {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			it := ParallelMap(NewIterator(Range(1, 100).Seq()).Filter(func(v int) bool { return v%2 == 0 }), 4, func(v int) int { return v + g })
+			sums[g] = Reduce(it, 0, func(acc, v int) int { return acc + v })
+		}()
+	}
+	wg.Wait()
+	for g, sum := range sums {
+		if want := 2550 + 50*g; sum != want {
+			t.Errorf("goroutine %d: sum = %d, want %d", g, sum, want)
+		}
+	}
+}
 
-	// 测试Map功能：将每个数字乘以2
-	it = CreateNumberIterator(1, 10)
-	mappedIt := it.(*lazyIterator).Map(func(v interface{}) interface{} {
-		return v.(int) * 2
-	})
-	fmt.Println("\n应用Map后（每个数字乘以2）:")
-	PrintAll(mappedIt)
+// chainOp 模糊测试中的一个组合操作：同时作用于迭代器和作为参考实现的切片
+type chainOp struct {
+	iter  func(*Iterator[int]) *Iterator[int]
+	slice func([]int) []int
+}
+
+// decodeOp 将一个字节解码为组合操作，低3位选择操作，其余位作为参数
+func decodeOp(b byte) chainOp {
+	arg := int(b >> 3)
+	switch b % 8 {
+	case 0:
+		return chainOp{
+			func(it *Iterator[int]) *Iterator[int] { return it.Map(func(v int) int { return v + arg }) },
+			func(s []int) []int {
+				out := make([]int, len(s))
+				for i, v := range s {
+					out[i] = v + arg
+				}
+				return out
+			},
+		}
+	case 1:
+		keep := func(v int) bool { return v%(arg%5+2) != 0 }
+		return chainOp{
+			func(it *Iterator[int]) *Iterator[int] { return it.Filter(keep) },
+			func(s []int) []int { return slices.DeleteFunc(slices.Clone(s), func(v int) bool { return !keep(v) }) },
+		}
+	case 2:
+		return chainOp{
+			func(it *Iterator[int]) *Iterator[int] { return it.Take(arg) },
+			func(s []int) []int { return s[:min(arg, len(s))] },
+		}
+	case 3:
+		return chainOp{
+			func(it *Iterator[int]) *Iterator[int] { return it.Skip(arg) },
+			func(s []int) []int { return s[min(arg, len(s)):] },
+		}
+	case 4:
+		small := func(v int) bool { return v < arg*8 }
+		return chainOp{
+			func(it *Iterator[int]) *Iterator[int] { return it.TakeWhile(small) },
+			func(s []int) []int {
+				if i := slices.IndexFunc(s, func(v int) bool { return !small(v) }); i >= 0 {
+					return s[:i]
+				}
+				return s
+			},
+		}
+	case 5:
+		return chainOp{
+			func(it *Iterator[int]) *Iterator[int] { return Distinct(it) },
+			func(s []int) []int {
+				seen := map[int]bool{}
+				return slices.DeleteFunc(slices.Clone(s), func(v int) bool {
+					dup := seen[v]
+					seen[v] = true
+					return dup
+				})
+			},
+		}
+	case 6:
+		return chainOp{
+			func(it *Iterator[int]) *Iterator[int] {
+				return ParallelMap(it, arg%4+1, func(v int) int { return v * 2 })
+			},
+			func(s []int) []int {
+				out := make([]int, len(s))
+				for i, v := range s {
+					out[i] = v * 2
+				}
+				return out
+			},
+		}
+	default:
+		return chainOp{
+			func(it *Iterator[int]) *Iterator[int] {
+				return FlatMap(it, func(v int) *Iterator[int] { return Range(v, v+arg%3) })
+			},
+			func(s []int) []int {
+				var out []int
+				for _, v := range s {
+					for i := v; i <= v+arg%3; i++ {
+						out = append(out, i)
+					}
+				}
+				return out
+			},
+		}
+	}
+}
 
-	// 测试Filter功能：只保留偶数
-	it = CreateNumberIterator(1, 10)
-	filteredIt := it.(*lazyIterator).Filter(func(v interface{}) bool {
-		return v.(int)%2 == 0 // 只保留偶数
+// FuzzChain 随机组合操作链，与切片参考实现的结果对比
+func FuzzChain(f *testing.F) {
+	f.Add([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, []byte{0x09, 0x08, 0x12})
+	f.Add([]byte{5, 5, 5, 1, 1}, []byte{0x05, 0x22, 0x0e})
+	f.Add([]byte{}, []byte{0x03, 0x04, 0x07})
+	f.Fuzz(func(t *testing.T, data, ops []byte) {
+		if len(ops) > 8 {
+			ops = ops[:8]
+		}
+		src := make([]int, len(data))
+		for i, b := range data {
+			src[i] = int(b)
+		}
+		it := NewIterator(slices.Values(src))
+		want := src
+		for _, b := range ops {
+			op := decodeOp(b)
+			it = op.iter(it)
+			want = op.slice(want)
+		}
+		if got := it.Collect(); !slices.Equal(got, want) && !(len(got) == 0 && len(want) == 0) {
+			t.Errorf("data=%v ops=%x: got %v, want %v", data, ops, got, want)
+		}
 	})
-	fmt.Println("\n应用Filter后（只保留偶数）:")
-	PrintAll(filteredIt)
-
-	// 测试Take功能：只取前5个元素
-	it = CreateNumberIterator(1, 10)
-	takenIt := it.(*lazyIterator).Take(5)
-	fmt.Println("\n应用Take后（只取前5个元素）:")
-	PrintAll(takenIt)
-
-	// 测试链式操作：先过滤出偶数，然后乘以3，最后取前2个
-	it = CreateNumberIterator(1, 10)
-	chainedIt := it.(*lazyIterator).
-		Filter(func(v interface{}) bool {
-			return v.(int)%2 == 0
-		}).(*lazyIterator).Map(func(v interface{}) interface{} {
-		return v.(int) * 3
-	}).(*lazyIterator).Take(2)
-
-	fmt.Println("\n链式操作（过滤偶数，乘以3，取前2个）:")
-	PrintAll(chainedIt)
 }
@@ -4,112 +4,72 @@ import "fmt"
 
 // LazyIterator 惰性迭代器接口
 // Next 返回迭代器的下一个元素和一个布尔值，布尔值表示是否还有更多元素
+// Stop 提前结束迭代，释放生成器占用的资源，停止会沿 Map/Filter/Take 链传递到数据源
 type LazyIterator interface {
 	Next() (interface{}, bool)
+	Stop()
 }
 
 // lazyIterator 实现惰性迭代器
+// 基于泛型 Iterator[interface{}] 的适配层，保留旧的非泛型 API
 type lazyIterator struct {
-	nextFunc func() (interface{}, bool)
+	it *Iterator[interface{}]
 }
 
 // Next 返回迭代器的下一个元素
 func (it *lazyIterator) Next() (interface{}, bool) {
-	return it.nextFunc()
+	return it.it.Next()
+}
+
+// Stop 停止迭代，可以重复调用
+func (it *lazyIterator) Stop() {
+	it.it.Stop()
+}
+
+// Close 等同于 Stop，便于作为 io.Closer 使用
+func (it *lazyIterator) Close() error {
+	return it.it.Close()
 }
 
 // NewLazyIterator 创建新的惰性迭代器
 // generator: 生成器函数，用于产生迭代器元素，当yield返回false时停止生成
 func NewLazyIterator(generator func(yield func(interface{}) bool)) LazyIterator {
-	ch := make(chan interface{})
-	done := make(chan struct{})
-
-	// 启动一个goroutine异步执行generator函数
-	// defer语句确保在函数退出时关闭channel
-	go func() {
-		defer close(ch)
-		// 调用generator函数，并传入一个yield函数
-		// yield函数用于将生成的值发送到channel中
-		generator(func(v interface{}) bool {
-			// 使用select语句实现非阻塞的channel操作
-			// 如果能成功发送到ch，则返回true继续生成
-			// 如果done channel有数据，则表示需要终止生成，返回false
-			select {
-			case ch <- v:
-				return true
-			case <-done:
-				return false
-			}
-		})
-	}()
-
-	return &lazyIterator{
-		nextFunc: func() (interface{}, bool) {
-			select {
-			case v, ok := <-ch:
-				// 如果channel已关闭，则关闭done channel
-				if !ok {
-					close(done)
-				}
-				return v, ok
-			case <-done:
-				return nil, false
-			}
-		},
-	}
+	return &lazyIterator{it: NewIterator(generator)}
 }
 
 // Map 对迭代器中的每个元素应用函数
 // f: 应用于每个元素的转换函数
 func (it *lazyIterator) Map(f func(interface{}) interface{}) LazyIterator {
-	return NewLazyIterator(func(yield func(interface{}) bool) {
-		for {
-			v, ok := it.Next()
-			if !ok {
-				return
-			}
-			if !yield(f(v)) {
-				return
-			}
-		}
-	})
+	return &lazyIterator{it: it.it.Map(f)}
 }
 
 // Filter 过滤迭代器中的元素
 // predicate: 判断元素是否应该被保留的谓词函数
 func (it *lazyIterator) Filter(predicate func(interface{}) bool) LazyIterator {
-	return NewLazyIterator(func(yield func(interface{}) bool) {
-		for {
-			v, ok := it.Next()
-			if !ok {
-				return
-			}
-			if predicate(v) {
-				if !yield(v) {
-					return
-				}
-			}
-		}
-	})
+	return &lazyIterator{it: it.it.Filter(predicate)}
 }
 
 // Take 取前n个元素
 // n: 要取的元素数量
 func (it *lazyIterator) Take(n int) LazyIterator {
-	return NewLazyIterator(func(yield func(interface{}) bool) {
-		count := 0
-		// 循环直到取够n个元素或迭代器耗尽
-		for count < n {
-			v, ok := it.Next()
-			if !ok {
-				return
-			}
-			if !yield(v) {
-				return
-			}
-			count++
+	return &lazyIterator{it: it.it.Take(n)}
+}
+
+// ToLazy 将泛型迭代器包装为旧的 LazyIterator
+func ToLazy[T any](it *Iterator[T]) LazyIterator {
+	return &lazyIterator{it: Map(it, func(v T) interface{} { return v })}
+}
+
+// FromLazy 将旧的 LazyIterator 转换为泛型迭代器，元素需要是 T 类型，否则会 panic
+func FromLazy[T any](l LazyIterator) *Iterator[T] {
+	return newPull(func() (T, bool) {
+		v, ok := l.Next()
+		if !ok {
+			var zero T
+			return zero, false
 		}
-	})
+		return v.(T), true
+	}, l.Stop)
 }
 
 // 辅助函数：创建一个包含指定范围数字的迭代器
@@ -125,6 +85,7 @@ func CreateNumberIterator(start, end int) LazyIterator {
 
 // 辅助函数：打印迭代器中的所有元素
 func PrintAll(it LazyIterator) {
+	defer it.Stop()
 	count := 0
 	for v, ok := it.Next(); ok; v, ok = it.Next() {
 		fmt.Printf("%v ", v)
@@ -187,4 +148,16 @@ func main() {
 
 	fmt.Println("\n链式操作（过滤偶数，乘以3，取前2个）:")
 	PrintAll(chainedIt)
+
+	// 泛型迭代器：无需类型断言，可以直接用于 for range
+	squares := Map(Range(1, 10).Filter(func(v int) bool {
+		return v%2 == 0
+	}), func(v int) string {
+		return fmt.Sprintf("%d²=%d", v, v*v)
+	}).Take(3)
+	fmt.Println("\n泛型迭代器（偶数的平方，取前3个）:")
+	for i, v := range squares.All() {
+		fmt.Printf("%d:%s ", i, v)
+	}
+	fmt.Println()
 }
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// checkNoLeaks 记录测试开始时的 goroutine 数量，测试结束后确认没有遗留的 goroutine（包括 iter.Pull 的协程）
+func checkNoLeaks(t *testing.T) {
+	t.Helper()
+	before := runtime.NumGoroutine()
+	t.Cleanup(func() {
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > before {
+			if time.Now().After(deadline) {
+				buf := make([]byte, 1<<16)
+				buf = buf[:runtime.Stack(buf, true)]
+				t.Errorf("goroutine 泄漏: 开始时 %d 个，结束时 %d 个\n%s", before, runtime.NumGoroutine(), buf)
+				return
+			}
+			runtime.Gosched()
+			time.Sleep(time.Millisecond)
+		}
+	})
+}
+
+// infinite 无限产生整数的数据源，只有被停止时才会结束
+func infinite(stopped *bool) *Iterator[int] {
+	return NewIterator(func(yield func(int) bool) {
+		defer func() { *stopped = true }()
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	})
+}
+
+func TestTakeStopsSource(t *testing.T) {
+	checkNoLeaks(t)
+	var stopped bool
+	it := Map(infinite(&stopped).Filter(func(v int) bool { return v%2 == 0 }), func(v int) int { return v * 3 }).Take(3)
+	for range 3 {
+		it.Next()
+	}
+	if _, ok := it.Next(); ok {
+		t.Fatal("Take(3) 返回了第4个元素")
+	}
+	if !stopped {
+		t.Error("Take 取够元素后数据源没有被停止")
+	}
+}
+
+func TestStopPropagatesToSource(t *testing.T) {
+	checkNoLeaks(t)
+	var stopped bool
+	it := Map(infinite(&stopped), func(v int) string { return strings.Repeat("x", v) }).Filter(func(s string) bool { return len(s) > 1 })
+	if v, ok := it.Next(); !ok || v != "xx" {
+		t.Fatalf("Next = %q, %v", v, ok)
+	}
+	it.Stop()
+	it.Stop() // 重复调用是安全的
+	if !stopped {
+		t.Error("Stop 没有传递到数据源")
+	}
+	if _, ok := it.Next(); ok {
+		t.Error("Stop 之后 Next 仍然返回元素")
+	}
+}
+
+func TestRangeBreakStops(t *testing.T) {
+	checkNoLeaks(t)
+	var stopped bool
+	var got []int
+	for v := range infinite(&stopped).Seq() {
+		if v == 3 {
+			break
+		}
+		got = append(got, v)
+	}
+	if !stopped || !slices.Equal(got, []int{0, 1, 2}) {
+		t.Errorf("got %v, stopped=%v", got, stopped)
+	}
+}
+
+func TestLazyIteratorStop(t *testing.T) {
+	checkNoLeaks(t)
+	it := CreateNumberIterator(1, 1000).(*lazyIterator).Filter(func(v interface{}) bool {
+		return v.(int)%2 == 0
+	}).(*lazyIterator).Take(2)
+	PrintAll(it)
+
+	// 只读取一个元素就放弃，Close 后生成器协程应当退出
+	partial := CreateNumberIterator(1, 1000).(*lazyIterator).Map(func(v interface{}) interface{} { return v })
+	partial.Next()
+	if err := partial.(*lazyIterator).Close(); err != nil {
+		t.Fatal(err)
+	}
+}
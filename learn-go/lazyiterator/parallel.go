@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// PanicError ParallelMap 中转换函数发生 panic 时通过 Err 返回的错误
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("lazyiterator: panic in ParallelMap: %v", e.Value)
+}
+
+// result 一个元素的并行转换结果
+type result[U any] struct {
+	value U
+	err   error
+}
+
+// parallel ParallelMap 的公共状态：上游只在消费者 goroutine 中读取，转换函数在独立的 goroutine 中执行，
+// 同时执行的转换不超过 n 个
+type parallel[T, U any] struct {
+	up       *Iterator[T]
+	n        int
+	f        func(T) U
+	wg       sync.WaitGroup
+	err      error
+	upClosed bool
+}
+
+// run 在新的 goroutine 中执行转换，panic 被转换为 PanicError 写入 out（out 需要有足够的缓冲，保证不会阻塞）
+func (p *parallel[T, U]) run(v T, out chan<- result[U]) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		var r result[U]
+		defer func() {
+			if e := recover(); e != nil {
+				r.err = &PanicError{Value: e, Stack: debug.Stack()}
+			}
+			out <- r
+		}()
+		r.value = p.f(v)
+	}()
+}
+
+// pull 从上游读取下一个元素，上游结束后不再读取
+func (p *parallel[T, U]) pull() (T, bool) {
+	if p.upClosed {
+		var zero T
+		return zero, false
+	}
+	v, ok := p.up.Next()
+	p.upClosed = !ok
+	return v, ok
+}
+
+// stop 停止上游并等待正在执行的转换结束，保证 Stop 返回后不再有遗留的 goroutine
+func (p *parallel[T, U]) stop() {
+	p.up.Stop()
+	p.wg.Wait()
+}
+
+func (p *parallel[T, U]) errFunc() error {
+	if p.err != nil {
+		return p.err
+	}
+	return p.up.Err()
+}
+
+// ParallelMap 使用最多n个 goroutine 并行执行转换函数，结果保持上游的顺序
+// 通过重排缓冲区实现：按读取顺序记录每个元素的结果 channel，始终等待最早的元素完成。
+// f 发生 panic 时迭代结束，Err 返回 *PanicError。消费者提前结束时需要调用 Stop。
+func ParallelMap[T, U any](it *Iterator[T], n int, f func(T) U) *Iterator[U] {
+	p := &parallel[T, U]{up: it, n: max(n, 1), f: f}
+	var pending []chan result[U]
+	out := &Iterator[U]{stop: p.stop, err: p.errFunc, interrupt: it.interruptNext}
+	out.next = func() (U, bool) {
+		for len(pending) < p.n {
+			v, ok := p.pull()
+			if !ok {
+				break
+			}
+			ch := make(chan result[U], 1)
+			p.run(v, ch)
+			pending = append(pending, ch)
+		}
+		var zero U
+		if len(pending) == 0 {
+			return zero, false
+		}
+		r := <-pending[0]
+		pending = pending[1:]
+		if r.err != nil {
+			p.err = r.err
+			out.Stop()
+			return zero, false
+		}
+		return r.value, true
+	}
+	return out
+}
+
+// ParallelMapUnordered 使用最多n个 goroutine 并行执行转换函数，按完成先后产生结果
+// 不需要等待较慢的元素，吞吐量高于 ParallelMap；其他行为与 ParallelMap 相同。
+func ParallelMapUnordered[T, U any](it *Iterator[T], n int, f func(T) U) *Iterator[U] {
+	p := &parallel[T, U]{up: it, n: max(n, 1), f: f}
+	results := make(chan result[U], p.n)
+	inflight := 0
+	out := &Iterator[U]{stop: p.stop, err: p.errFunc, interrupt: it.interruptNext}
+	out.next = func() (U, bool) {
+		for inflight < p.n {
+			v, ok := p.pull()
+			if !ok {
+				break
+			}
+			p.run(v, results)
+			inflight++
+		}
+		var zero U
+		if inflight == 0 {
+			return zero, false
+		}
+		r := <-results
+		inflight--
+		if r.err != nil {
+			p.err = r.err
+			out.Stop()
+			return zero, false
+		}
+		return r.value, true
+	}
+	return out
+}
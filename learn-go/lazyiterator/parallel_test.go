@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// trackInflight 包装转换函数，记录同时执行的最大数量
+func trackInflight(maxInflight *atomic.Int32, f func(int) int) func(int) int {
+	var cur atomic.Int32
+	return func(v int) int {
+		n := cur.Add(1)
+		defer cur.Add(-1)
+		for {
+			m := maxInflight.Load()
+			if n <= m || maxInflight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		return f(v)
+	}
+}
+
+// jitter 让元素以不同的耗时完成，打乱完成顺序
+func jitter(v int) int {
+	time.Sleep(time.Duration(v%4) * time.Millisecond)
+	return v * v
+}
+
+func TestParallelMapOrdered(t *testing.T) {
+	checkNoLeaks(t)
+	var maxInflight atomic.Int32
+	got := ParallelMap(Range(1, 50), 4, trackInflight(&maxInflight, jitter)).Collect()
+	want := Map(Range(1, 50), jitter).Collect()
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if m := maxInflight.Load(); m > 4 || m < 2 {
+		t.Errorf("最大并发 %d，期望在 [2, 4] 之间", m)
+	}
+}
+
+func TestParallelMapUnordered(t *testing.T) {
+	checkNoLeaks(t)
+	var maxInflight atomic.Int32
+	got := ParallelMapUnordered(Range(1, 50), 3, trackInflight(&maxInflight, jitter)).Collect()
+	want := Map(Range(1, 50), jitter).Collect()
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if m := maxInflight.Load(); m > 3 {
+		t.Errorf("最大并发 %d，超过了 3", m)
+	}
+}
+
+func TestParallelMapPanic(t *testing.T) {
+	for name, pm := range map[string]func(*Iterator[int], int, func(int) int) *Iterator[int]{
+		"ordered":   ParallelMap[int, int],
+		"unordered": ParallelMapUnordered[int, int],
+	} {
+		t.Run(name, func(t *testing.T) {
+			checkNoLeaks(t)
+			var stopped bool
+			it := pm(infinite(&stopped), 4, func(v int) int {
+				if v == 10 {
+					panic("bad element")
+				}
+				return v
+			})
+			n := Count(it)
+			var pe *PanicError
+			if !errors.As(it.Err(), &pe) || pe.Value != "bad element" || len(pe.Stack) == 0 {
+				t.Fatalf("Err = %v, want *PanicError", it.Err())
+			}
+			if n > 13 || !stopped {
+				t.Errorf("panic 后读取了 %d 个元素, stopped=%v", n, stopped)
+			}
+		})
+	}
+}
+
+func TestParallelMapStopEarly(t *testing.T) {
+	checkNoLeaks(t)
+	var stopped bool
+	it := ParallelMap(infinite(&stopped), 8, func(v int) int {
+		time.Sleep(time.Millisecond)
+		return v
+	})
+	if got := it.Take(3).Collect(); !slices.Equal(got, []int{0, 1, 2}) {
+		t.Errorf("got %v", got)
+	}
+	if !stopped {
+		t.Error("Stop 没有传递到数据源")
+	}
+}
+
+func TestParallelMapUpstreamError(t *testing.T) {
+	checkNoLeaks(t)
+	errSource := errors.New("source failed")
+	src := FlatMap(Range(1, 2), func(v int) *Iterator[int] {
+		if v == 2 {
+			return Paginate(t.Context(), func(_ context.Context, _ int) ([]int, bool, error) { return nil, false, errSource })
+		}
+		return Range(1, 3)
+	})
+	got, err := ParallelMapUnordered(src, 2, func(v int) int { return v }).CollectErr()
+	slices.Sort(got)
+	if !errors.Is(err, errSource) || !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("got %v, %v", got, err)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sync"
+)
+
+// 本文件提供常用的数据源：按需读取，不会一次性把全部数据加载到内存
+
+// Lines 逐行读取 r，产生的行不包含换行符；读取出错时通过 Err 返回
+// 单行最长 1MB，超过时 Err 返回 bufio.ErrTooLong
+func Lines(r io.Reader) *Iterator[string] {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	return &Iterator[string]{
+		next: func() (string, bool) {
+			if !sc.Scan() {
+				return "", false
+			}
+			return sc.Text(), true
+		},
+		err: sc.Err,
+	}
+}
+
+// WalkEntry 目录遍历得到的一项
+type WalkEntry struct {
+	Path string
+	fs.DirEntry
+}
+
+// WalkDir 按 fs.WalkDir 的顺序遍历 fsys 中以 root 为根的目录树，包括 root 本身
+// 遍历出错时迭代结束并通过 Err 返回；本地目录可以使用 os.DirFS 创建 fsys
+func WalkDir(ctx context.Context, fsys fs.FS, root string) *Iterator[WalkEntry] {
+	return NewContextIterator(ctx, func(ctx context.Context, yield func(WalkEntry, error) bool) {
+		fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				yield(WalkEntry{}, err)
+				return fs.SkipAll
+			}
+			if !yield(WalkEntry{Path: p, DirEntry: d}, nil) {
+				return fs.SkipAll
+			}
+			return nil
+		})
+	})
+}
+
+// FromChan 从 channel 接收元素，channel 关闭时结束；ctx 取消时结束并通过 Err 返回 ctx.Err()。
+// channel 长时间没有数据时，下游的 Buffer 被 Stop 会中断正在等待的接收
+func FromChan[T any](ctx context.Context, ch <-chan T) *Iterator[T] {
+	var err error
+	interrupted := make(chan struct{})
+	var once sync.Once
+	return &Iterator[T]{
+		next: func() (T, bool) {
+			var zero T
+			if err != nil {
+				return zero, false
+			}
+			select {
+			case v, ok := <-ch:
+				return v, ok
+			case <-ctx.Done():
+				err = ctx.Err()
+				return zero, false
+			case <-interrupted:
+				return zero, false
+			}
+		},
+		err:       func() error { return err },
+		interrupt: func() { once.Do(func() { close(interrupted) }) },
+	}
+}
+
+// ScanRows 逐行读取查询结果，scan 负责把当前行扫描到 dest 中
+// 迭代结束或 Stop 时关闭 rows；扫描或读取出错时通过 Err 返回
+func ScanRows[T any](rows *sql.Rows, scan func(rows *sql.Rows, dest *T) error) *Iterator[T] {
+	var err error
+	return &Iterator[T]{
+		next: func() (T, bool) {
+			var v T
+			if err != nil || !rows.Next() {
+				if err == nil {
+					err = rows.Err()
+				}
+				rows.Close()
+				return v, false
+			}
+			if err = scan(rows, &v); err != nil {
+				rows.Close()
+				return v, false
+			}
+			return v, true
+		},
+		stop: func() { rows.Close() },
+		err:  func() error { return err },
+	}
+}
+
+// JSONArray 逐个解码 JSON 数组中的元素，不需要把整个文档读入内存
+// keys 为数组在外层对象中的路径，例如 Iceberg 的分页结果 {"items": [...]} 使用 JSONArray[T](r, "items")
+func JSONArray[T any](r io.Reader, keys ...string) *Iterator[T] {
+	dec := json.NewDecoder(r)
+	var err error
+	started := false
+	return &Iterator[T]{
+		next: func() (T, bool) {
+			var v T
+			if err != nil {
+				return v, false
+			}
+			if !started {
+				started = true
+				if err = seekArray(dec, keys); err != nil {
+					return v, false
+				}
+			}
+			if !dec.More() {
+				return v, false
+			}
+			if err = dec.Decode(&v); err != nil {
+				return v, false
+			}
+			return v, true
+		},
+		err: func() error { return err },
+	}
+}
+
+// seekArray 沿 keys 进入嵌套的对象，读到目标数组的起始 '[' 为止
+func seekArray(dec *json.Decoder, keys []string) error {
+	for i, key := range keys {
+		if err := expectDelim(dec, '{', path.Join(keys[:i]...)); err != nil {
+			return err
+		}
+		for {
+			if !dec.More() {
+				return fmt.Errorf("lazyiterator: JSON 中缺少字段 %q", path.Join(keys[:i+1]...))
+			}
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if tok == key {
+				break
+			}
+			// 跳过不需要的字段值
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+	}
+	return expectDelim(dec, '[', path.Join(keys...))
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim, at string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return fmt.Errorf("lazyiterator: JSON 位置 %q 期望 %v，实际为 %v", "/"+at, want, tok)
+	}
+	return nil
+}
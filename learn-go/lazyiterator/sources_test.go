@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLines(t *testing.T) {
+	got := Lines(strings.NewReader("a\nbb\r\n\nccc")).Collect()
+	if want := []string{"a", "bb", "", "ccc"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	long := Lines(strings.NewReader(strings.Repeat("x", 2<<20)))
+	if _, err := long.CollectErr(); err == nil {
+		t.Error("超长的行应当返回错误")
+	}
+}
+
+func TestWalkDir(t *testing.T) {
+	checkNoLeaks(t)
+	fsys := fstest.MapFS{
+		"data/a.json":     {Data: []byte("{}")},
+		"data/sub/b.json": {Data: []byte("{}")},
+		"data/sub/c.txt":  {Data: []byte("c")},
+	}
+	jsonFiles := Map(WalkDir(context.Background(), fsys, "data").Filter(func(e WalkEntry) bool {
+		return !e.IsDir() && strings.HasSuffix(e.Path, ".json")
+	}), func(e WalkEntry) string { return e.Path })
+	if got := jsonFiles.Collect(); !slices.Equal(got, []string{"data/a.json", "data/sub/b.json"}) {
+		t.Errorf("got %v", got)
+	}
+
+	if _, err := WalkDir(context.Background(), fsys, "missing").CollectErr(); err == nil {
+		t.Error("遍历不存在的目录应当返回错误")
+	}
+}
+
+func TestFromChan(t *testing.T) {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+	if got := FromChan(context.Background(), ch).Collect(); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("got %v", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	it := FromChan(ctx, make(chan int))
+	if _, ok := it.Next(); ok || !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("Err = %v, want context.Canceled", it.Err())
+	}
+}
+
+// icebergTable 与 Iceberg 分页结果中的表项结构相同的最小定义
+type icebergTable struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+}
+
+func TestJSONArray(t *testing.T) {
+	doc := `{
+		"kind": "PagedList",
+		"metadata": {"totalItems": 3, "items": "not this one"},
+		"items": [
+			{"kind": "IcebergTable", "metadata": {"name": "a.devices"}},
+			{"kind": "IcebergTable", "metadata": {"name": "a.users"}},
+			{"kind": "IcebergTable", "metadata": {"name": "b.devices"}}
+		]
+	}`
+	names := Map(JSONArray[icebergTable](strings.NewReader(doc), "items"), func(t icebergTable) string { return t.Metadata.Name })
+	if got := names.Collect(); !slices.Equal(got, []string{"a.devices", "a.users", "b.devices"}) {
+		t.Errorf("got %v", got)
+	}
+
+	if got := JSONArray[int](strings.NewReader(" [1, 2, 3] ")).Collect(); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("got %v", got)
+	}
+
+	for _, bad := range []struct{ doc, key string }{
+		{`{"items": {}}`, "items"},
+		{`{"other": []}`, "items"},
+		{`[1, "x"]`, ""},
+		{`[1, 2`, ""},
+	} {
+		var keys []string
+		if bad.key != "" {
+			keys = []string{bad.key}
+		}
+		if _, err := JSONArray[int](strings.NewReader(bad.doc), keys...).CollectErr(); err == nil {
+			t.Errorf("JSONArray(%q): want error", bad.doc)
+		}
+	}
+}
+
+// 以下是只支持单条查询的内存 SQL 驱动，用于测试 ScanRows
+
+type album struct {
+	ID     int64
+	Title  string
+	Artist string
+}
+
+type fakeDriver struct{ rows [][]driver.Value }
+type fakeConn struct{ d *fakeDriver }
+type fakeStmt struct{ d *fakeDriver }
+type fakeRows struct{ rows [][]driver.Value }
+
+// Connect 和 Driver 让 fakeDriver 同时实现 driver.Connector，通过 sql.OpenDB 使用，不需要全局注册
+func (d *fakeDriver) Connect(context.Context) (driver.Conn, error) { return &fakeConn{d}, nil }
+func (d *fakeDriver) Driver() driver.Driver                        { return d }
+
+func (d *fakeDriver) Open(string) (driver.Conn, error)  { return &fakeConn{d}, nil }
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return &fakeStmt{c.d}, nil }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+func (s *fakeStmt) Close() error                        { return nil }
+func (s *fakeStmt) NumInput() int                       { return -1 }
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{rows: s.d.rows}, nil
+}
+func (r *fakeRows) Columns() []string { return []string{"id", "title", "artist"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestScanRows(t *testing.T) {
+	db := sql.OpenDB(&fakeDriver{rows: [][]driver.Value{
+		{int64(1), "Blue Train", "John Coltrane"},
+		{int64(2), "Giant Steps", "John Coltrane"},
+		{int64(3), "Jeru", "Gerry Mulligan"},
+	}})
+	defer db.Close()
+
+	scanAlbum := func(rows *sql.Rows, a *album) error {
+		return rows.Scan(&a.ID, &a.Title, &a.Artist)
+	}
+	rows, err := db.Query("SELECT * FROM album")
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := Map(ScanRows(rows, scanAlbum).Filter(func(a album) bool { return a.Artist == "John Coltrane" }), func(a album) string { return a.Title })
+	if got, err := titles.CollectErr(); err != nil || !slices.Equal(got, []string{"Blue Train", "Giant Steps"}) {
+		t.Errorf("got %v, %v", got, err)
+	}
+
+	// 提前停止时关闭 rows，连接归还连接池后可以继续查询
+	rows, _ = db.Query("SELECT * FROM album")
+	if first, ok := First(ScanRows(rows, scanAlbum)); !ok || first.ID != 1 {
+		t.Errorf("First = %+v, %v", first, ok)
+	}
+	if rows.Next() {
+		t.Error("Stop 之后 rows 没有被关闭")
+	}
+
+	rows, _ = db.Query("SELECT * FROM album")
+	errScan := errors.New("scan failed")
+	_, err = ScanRows(rows, func(*sql.Rows, *album) error { return errScan }).CollectErr()
+	if !errors.Is(err, errScan) {
+		t.Errorf("err = %v, want %v", err, errScan)
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"math/rand"
 )
 
+// ErrEmptyName is returned when a greeting is requested without a name.
+var ErrEmptyName = errors.New("empty name")
+
 // Hello returns a greeting for the named person.
 func Hello(name string) (string, error) {
 	// If no name was given, return an error with a message.
 	if name == "" {
-		return name, errors.New("empty name")
+		return name, ErrEmptyName
 	}
 	// Create a message using a random format.
 	message := fmt.Sprintf(randomFormat(), name)
@@ -0,0 +1,221 @@
+package greetings
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Message catalogs, one JSON file per language named after its
+// language tag (en.json, zh.json, ...).
+//
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// Catalog holds the messages of one language.
+type Catalog struct {
+	// Formats are greeting formats with a single %v for the name.
+	// One of them is picked for every greeting.
+	Formats []string `json:"formats"`
+	// Separator joins several names into one greeting.
+	Separator string `json:"separator"`
+	// Reply formats a reply to something the person said.
+	Reply string `json:"reply"`
+}
+
+// Greeting is a greeting message and the locale it was written in.
+type Greeting struct {
+	Message string
+	Locale  string
+}
+
+// Localizer produces greetings in the languages of its catalogs.
+// It is safe for concurrent use.
+type Localizer struct {
+	catalogs map[string]Catalog
+	fallback string
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// Option configures a Localizer.
+type Option func(*Localizer)
+
+// WithSeed makes the choice of greeting formats deterministic, so
+// that tests can rely on the messages a Localizer returns.
+func WithSeed(seed uint64) Option {
+	return func(l *Localizer) { l.rand = rand.New(rand.NewPCG(seed, seed)) }
+}
+
+// WithFallback sets the locale used when none of the requested
+// languages has a catalog. The default is "en".
+func WithFallback(locale string) Option {
+	return func(l *Localizer) { l.fallback = normalize(locale) }
+}
+
+// WithCatalog adds a catalog for locale, replacing the embedded one
+// if there is one.
+func WithCatalog(locale string, c Catalog) Option {
+	return func(l *Localizer) { l.catalogs[normalize(locale)] = c }
+}
+
+// NewLocalizer returns a Localizer with the embedded catalogs.
+func NewLocalizer(opts ...Option) (*Localizer, error) {
+	l := &Localizer{
+		catalogs: make(map[string]Catalog),
+		fallback: "en",
+		rand:     rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+	files, err := fs.Glob(localeFS, "locales/*.json")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := localeFS.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var c Catalog
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("greetings: %s: %w", file, err)
+		}
+		l.catalogs[strings.TrimSuffix(path.Base(file), ".json")] = c
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	for locale, c := range l.catalogs {
+		if len(c.Formats) == 0 {
+			return nil, fmt.Errorf("greetings: catalog %q has no formats", locale)
+		}
+	}
+	if _, ok := l.catalogs[l.fallback]; !ok {
+		return nil, fmt.Errorf("greetings: no catalog for fallback locale %q", l.fallback)
+	}
+	return l, nil
+}
+
+// Locales returns the locales that have a catalog, sorted.
+func (l *Localizer) Locales() []string {
+	locales := make([]string, 0, len(l.catalogs))
+	for locale := range l.catalogs {
+		locales = append(locales, locale)
+	}
+	slices.Sort(locales)
+	return locales
+}
+
+// Match picks the best locale for a list of language preferences in
+// the form of an HTTP Accept-Language header, such as
+// "zh-CN,zh;q=0.9,en;q=0.8". Languages are tried by decreasing
+// quality; a regional tag such as "zh-CN" also matches its base
+// language "zh". If nothing matches, Match returns the fallback.
+func (l *Localizer) Match(accept string) string {
+	for _, tag := range parseAcceptLanguage(accept) {
+		if tag == "*" {
+			break
+		}
+		if _, ok := l.catalogs[tag]; ok {
+			return tag
+		}
+		if base, _, ok := strings.Cut(tag, "-"); ok {
+			if _, ok := l.catalogs[base]; ok {
+				return base
+			}
+		}
+	}
+	return l.fallback
+}
+
+// Hello returns a greeting for the named person in the best locale
+// for accept (see Match).
+func (l *Localizer) Hello(accept, name string) (Greeting, error) {
+	if name == "" {
+		return Greeting{}, ErrEmptyName
+	}
+	locale := l.Match(accept)
+	return Greeting{Message: fmt.Sprintf(l.format(locale), name), Locale: locale}, nil
+}
+
+// HelloAll returns one greeting for all the named people.
+func (l *Localizer) HelloAll(accept string, names []string) (Greeting, error) {
+	if len(names) == 0 || slices.Contains(names, "") {
+		return Greeting{}, ErrEmptyName
+	}
+	locale := l.Match(accept)
+	c := l.catalogs[locale]
+	return Greeting{
+		Message: fmt.Sprintf(l.format(locale), strings.Join(names, c.Separator)),
+		Locale:  locale,
+	}, nil
+}
+
+// Reply returns a greeting for the named person followed by a reply
+// to what they said.
+func (l *Localizer) Reply(accept, name, said string) (Greeting, error) {
+	g, err := l.Hello(accept, name)
+	if err != nil {
+		return g, err
+	}
+	if reply := l.catalogs[g.Locale].Reply; reply != "" {
+		g.Message += " " + fmt.Sprintf(reply, said)
+	}
+	return g, nil
+}
+
+// format picks one of the formats of locale.
+func (l *Localizer) format(locale string) string {
+	formats := l.catalogs[locale].Formats
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return formats[l.rand.IntN(len(formats))]
+}
+
+// parseAcceptLanguage returns the language tags of an Accept-Language
+// value ordered by decreasing quality. Tags with q=0 are dropped.
+func parseAcceptLanguage(accept string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(accept, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = normalize(tag)
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+// normalize lowercases a language tag and uses "-" as the separator,
+// so that "zh_CN" and "zh-cn" both become "zh-cn".
+func normalize(tag string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(tag)), "_", "-")
+}
@@ -0,0 +1,104 @@
+package greetings
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestMatch checks that Accept-Language values pick the expected
+// catalog, falling back to the base language and then to the default.
+func TestMatch(t *testing.T) {
+	l, err := NewLocalizer(WithFallback("zh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		accept, want string
+	}{
+		{"", "zh"},
+		{"en", "en"},
+		{"EN_us", "en"},
+		{"zh-CN,zh;q=0.9,en;q=0.8", "zh"},
+		{"fr-FR, en;q=0.5, ja;q=0.7", "ja"},
+		{"ja;q=0, en", "en"},
+		{"fr, de", "zh"},
+		{"*", "zh"},
+		{"en;q=bad, ja", "ja"},
+	}
+	for _, tt := range tests {
+		if got := l.Match(tt.accept); got != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+}
+
+// TestLocalizerSeed checks that two localizers with the same seed
+// pick the same formats.
+func TestLocalizerSeed(t *testing.T) {
+	a, err := NewLocalizer(WithSeed(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewLocalizer(WithSeed(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for range 20 {
+		x, _ := a.Hello("zh", "Gladys")
+		y, _ := b.Hello("zh", "Gladys")
+		if x != y {
+			t.Fatalf("same seed, different greetings: %q and %q", x.Message, y.Message)
+		}
+		seen[x.Message] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("20 greetings used only %d format(s)", len(seen))
+	}
+}
+
+// TestLocalizerMessages uses a one-format catalog so that the
+// messages are fixed.
+func TestLocalizerMessages(t *testing.T) {
+	l, err := NewLocalizer(WithCatalog("zh", Catalog{
+		Formats:   []string{"你好, %v!"},
+		Separator: "、",
+		Reply:     "你说: %v",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := l.Hello("zh-TW", "Gladys"); got != (Greeting{"你好, Gladys!", "zh"}) {
+		t.Errorf("Hello = %+v", got)
+	}
+	if got, _ := l.HelloAll("zh", []string{"a", "b"}); got.Message != "你好, a、b!" {
+		t.Errorf("HelloAll = %+v", got)
+	}
+	if got, _ := l.Reply("zh", "Gladys", "hi"); got.Message != "你好, Gladys! 你说: hi" {
+		t.Errorf("Reply = %+v", got)
+	}
+	if got, _ := l.Hello("de", "Gladys"); got.Locale != "en" || !strings.Contains(got.Message, "Gladys") {
+		t.Errorf("Hello with default fallback = %+v", got)
+	}
+	if _, err := l.Hello("en", ""); !errors.Is(err, ErrEmptyName) {
+		t.Errorf("Hello(\"\") error = %v", err)
+	}
+	if _, err := l.HelloAll("en", []string{"a", ""}); !errors.Is(err, ErrEmptyName) {
+		t.Errorf("HelloAll with an empty name error = %v", err)
+	}
+	if got := l.Locales(); !slices.Equal(got, []string{"en", "ja", "zh"}) {
+		t.Errorf("Locales = %q", got)
+	}
+}
+
+// TestNewLocalizerErrors checks the validation of options.
+func TestNewLocalizerErrors(t *testing.T) {
+	if _, err := NewLocalizer(WithFallback("fr")); err == nil {
+		t.Error("fallback without a catalog: want error")
+	}
+	if _, err := NewLocalizer(WithCatalog("fr", Catalog{})); err == nil {
+		t.Error("catalog without formats: want error")
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import "strings"
+
+// diffOp 一行在差异中的类型
+type diffOp int
+
+const (
+	diffEqual diffOp = iota
+	diffDelete
+	diffInsert
+)
+
+// diffLine 差异中的一行
+type diffLine struct {
+	Op   diffOp
+	Text string
+}
+
+// Prefix 返回统一格式（unified diff）中的行首标记
+func (l diffLine) Prefix() string {
+	return [...]string{" ", "-", "+"}[l.Op]
+}
+
+// Class 返回模板中使用的 CSS 类名
+func (l diffLine) Class() string {
+	return [...]string{"eq", "del", "ins"}[l.Op]
+}
+
+// splitLines 把页面内容拆分为行，统一换行符并忽略末尾的换行
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// maxDiffCells 最长公共子序列表格的最大单元格数。表格占用 len(a)*len(b) 的内存和计算时间，
+// 超过时不再逐行对比，改为整段删除再整段插入，避免巨大的页面耗尽服务器资源
+var maxDiffCells = 4 << 20
+
+// lineDiff 基于最长公共子序列计算从 a 到 b 的逐行差异，exact 为 false 表示改动范围过大，
+// 结果是整段删除再整段插入的粗略差异。
+// 先去掉相同的开头和结尾，wiki 页面通常只改动其中一小段
+func lineDiff(a, b []string) (lines []diffLine, exact bool) {
+	var prefix, suffix []diffLine
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		prefix = append(prefix, diffLine{diffEqual, a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append([]diffLine{{diffEqual, a[len(a)-1]}}, suffix...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	if len(b) > 0 && len(a) > maxDiffCells/len(b) {
+		lines = prefix
+		for _, l := range a {
+			lines = append(lines, diffLine{diffDelete, l})
+		}
+		for _, l := range b {
+			lines = append(lines, diffLine{diffInsert, l})
+		}
+		return append(lines, suffix...), false
+	}
+
+	// lcs[i][j] 为 a[i:] 和 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines = prefix
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{diffEqual, a[i]})
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			// 删除和插入同样可行时先输出删除，被修改的行显示为先删后增
+			lines = append(lines, diffLine{diffDelete, a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{diffInsert, b[j]})
+			j++
+		}
+	}
+	return append(lines, suffix...), true
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// format 把差异转换为统一格式的文本，便于比较
+func format(lines []diffLine) string {
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(l.Prefix() + l.Text + "\n")
+	}
+	return b.String()
+}
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		name, a, b, want string
+	}{
+		{"相同", "a\nb", "a\nb\n", " a\n b\n"},
+		{"空白到内容", "", "a\nb", "+a\n+b\n"},
+		{"内容到空白", "a\nb", "", "-a\n-b\n"},
+		{"修改一行", "a\nb\nc", "a\nB\nc", " a\n-b\n+B\n c\n"},
+		{"插入和删除", "a\nb\nc\nd", "a\nc\nd\ne", " a\n-b\n c\n d\n+e\n"},
+		{"换行符", "a\r\nb\r\n", "a\nb", " a\n b\n"},
+		{"移动", "x\na\nb\ny", "x\nb\na\ny", " x\n-a\n b\n+a\n y\n"},
+	}
+	for _, tt := range tests {
+		lines, exact := lineDiff(splitLines(tt.a), splitLines(tt.b))
+		if got := format(lines); got != tt.want || !exact {
+			t.Errorf("%s: exact=%v\ngot\n%s\nwant\n%s", tt.name, exact, got, tt.want)
+		}
+	}
+}
+
+func TestLineDiffTooLarge(t *testing.T) {
+	defer func(n int) { maxDiffCells = n }(maxDiffCells)
+	maxDiffCells = 4
+
+	// 去掉相同的开头和结尾后剩下 3x2 行，超过上限时整段删除再整段插入
+	lines, exact := lineDiff(splitLines("x\na\nb\nc\ny"), splitLines("x\nb\nd\ny"))
+	if want := " x\n-a\n-b\n-c\n+b\n+d\n y\n"; format(lines) != want || exact {
+		t.Errorf("exact=%v\ngot\n%s\nwant\n%s", exact, format(lines), want)
+	}
+	if _, exact := lineDiff(splitLines("a\nb"), splitLines("c\nd")); !exact {
+		t.Error("2x2 行没有超过上限，应当逐行对比")
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Revision 页面的一个历史版本，每次保存或回退都追加一个
+type Revision struct {
+	// Rev 版本号，从 1 开始递增
+	Rev     int       `json:"rev"`
+	Author  string    `json:"author"`
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+	Body    string    `json:"body"`
+}
+
+// anonymous 没有填写作者时记录的名字
+const anonymous = "匿名"
+
+// historyFile 返回页面历史在数据目录 dir 中的文件名，每行一个 JSON 格式的 Revision
+func historyFile(dir, title string) string {
+	return filepath.Join(dir, title+".history.jsonl")
+}
+
+// loadHistory 按版本号从小到大返回页面的全部版本，没有历史时返回空列表
+func loadHistory(dir, title string) ([]Revision, error) {
+	f, err := os.Open(historyFile(dir, title))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var revs []Revision
+	sc := bufio.NewScanner(f)
+	// 一行是一个版本的全文，放宽默认 64KB 的行长度限制
+	sc.Buffer(nil, 16<<20)
+	for sc.Scan() {
+		var rev Revision
+		if err := json.Unmarshal(sc.Bytes(), &rev); err != nil {
+			return nil, fmt.Errorf("%s 第 %d 行: %w", historyFile(dir, title), len(revs)+1, err)
+		}
+		revs = append(revs, rev)
+	}
+	return revs, sc.Err()
+}
+
+// appendRevision 在页面历史的末尾追加一个版本
+func appendRevision(dir, title string, rev Revision) error {
+	line, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(historyFile(dir, title), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// implicitRevision 有历史功能之前创建的页面没有历史文件，把 .txt 中的原有内容视为第 1 版。
+// 页面不存在时 ok 为 false
+func implicitRevision(dir, title string) (rev Revision, ok bool, err error) {
+	fi, err := os.Stat(pageFile(dir, title))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Revision{}, false, nil
+	}
+	if err != nil {
+		return Revision{}, false, err
+	}
+	p, err := loadPage(dir, title)
+	if err != nil {
+		return Revision{}, false, err
+	}
+	return Revision{Rev: 1, Author: anonymous, Time: fi.ModTime(), Message: "启用历史之前的内容", Body: string(p.Body)}, true, nil
+}
+
+// pageHistory 返回页面的全部版本，没有历史文件的已有页面返回隐含的第 1 版
+func pageHistory(dir, title string) ([]Revision, error) {
+	revs, err := loadHistory(dir, title)
+	if err != nil || len(revs) > 0 {
+		return revs, err
+	}
+	rev, ok, err := implicitRevision(dir, title)
+	if !ok {
+		return nil, err
+	}
+	return []Revision{rev}, nil
+}
+
+// savePage 把 body 保存为页面的新版本：先更新 .txt 中的当前内容，再追加到历史；
+// 追加失败时恢复原来的内容，.txt 和历史的最后一个版本始终一致。
+// 内容没有变化时不产生新版本。有历史功能之前创建的页面，先把原有内容记为第 1 版
+func (wk *wiki) savePage(title, body, author, message string) error {
+	wk.mu.Lock()
+	defer wk.mu.Unlock()
+
+	revs, err := loadHistory(wk.dir, title)
+	if err != nil {
+		return err
+	}
+	var pending []Revision
+	if len(revs) == 0 {
+		rev, ok, err := implicitRevision(wk.dir, title)
+		if err != nil {
+			return err
+		}
+		if ok {
+			revs = append(revs, rev)
+			pending = append(pending, rev)
+		}
+	}
+	if len(revs) > 0 && revs[len(revs)-1].Body == body {
+		return nil
+	}
+
+	if author = strings.TrimSpace(author); author == "" {
+		author = anonymous
+	}
+	pending = append(pending, Revision{
+		Rev:     len(revs) + 1,
+		Author:  author,
+		Time:    time.Now(),
+		Message: strings.TrimSpace(message),
+		Body:    body,
+	})
+	p := &Page{Title: title, Body: []byte(body)}
+	if err := p.save(wk.dir); err != nil {
+		return err
+	}
+	for _, rev := range pending {
+		if err := appendRevision(wk.dir, title, rev); err != nil {
+			return errors.Join(err, wk.restorePage(title, revs))
+		}
+	}
+	return nil
+}
+
+// restorePage 把 .txt 恢复为 revs 中最后一个版本的内容，没有版本时删除页面
+func (wk *wiki) restorePage(title string, revs []Revision) error {
+	if len(revs) == 0 {
+		return os.Remove(pageFile(wk.dir, title))
+	}
+	p := &Page{Title: title, Body: []byte(revs[len(revs)-1].Body)}
+	return p.save(wk.dir)
+}
+
+// findRevision 返回版本号为 n 的版本
+func findRevision(revs []Revision, n int) (Revision, bool) {
+	if n < 1 || n > len(revs) {
+		return Revision{}, false
+	}
+	return revs[n-1], true
+}
+
+// historyPage history 模板的数据
+type historyPage struct {
+	Title string
+	// Revisions 按版本号从大到小排列，最新的在前
+	Revisions []Revision
+	Latest    int
+}
+
+// historyHandler 列出页面的全部版本
+func (wk *wiki) historyHandler(w http.ResponseWriter, r *http.Request, title string) {
+	revs, err := wk.pageHistory(title)
+	if err != nil {
+		log.Printf("读取页面 %s 的历史失败: %v", title, err)
+		http.Error(w, "读取历史失败", http.StatusInternalServerError)
+		return
+	}
+	if len(revs) == 0 {
+		notFound(w, r)
+		return
+	}
+	data := historyPage{Title: title, Latest: len(revs)}
+	for i := len(revs) - 1; i >= 0; i-- {
+		data.Revisions = append(data.Revisions, revs[i])
+	}
+	renderStatus(w, http.StatusOK, "history", data)
+}
+
+// diffPage diff 模板的数据
+type diffPage struct {
+	Title    string
+	From, To Revision
+	Lines    []diffLine
+	// Exact 为 false 时改动范围过大，Lines 是整段删除再整段插入的粗略差异
+	Exact bool
+}
+
+// diffHandler 显示两个版本之间的逐行差异。to 默认为最新版本，from 默认为 to 的上一个版本，
+// from 为 0 表示空白页面，用于查看第 1 版的全部内容
+func (wk *wiki) diffHandler(w http.ResponseWriter, r *http.Request, title string) {
+	revs, err := wk.pageHistory(title)
+	if err != nil {
+		log.Printf("读取页面 %s 的历史失败: %v", title, err)
+		http.Error(w, "读取历史失败", http.StatusInternalServerError)
+		return
+	}
+	if len(revs) == 0 {
+		notFound(w, r)
+		return
+	}
+	revParam := func(name string, def int) (int, error) {
+		v := r.FormValue(name)
+		if v == "" {
+			return def, nil
+		}
+		return strconv.Atoi(v)
+	}
+	to, err := revParam("to", len(revs))
+	if err != nil {
+		http.Error(w, "to 不是合法的版本号", http.StatusBadRequest)
+		return
+	}
+	from, err := revParam("from", to-1)
+	if err != nil {
+		http.Error(w, "from 不是合法的版本号", http.StatusBadRequest)
+		return
+	}
+
+	data := diffPage{Title: title}
+	var ok bool
+	if data.To, ok = findRevision(revs, to); !ok {
+		http.Error(w, fmt.Sprintf("版本 %d 不存在", to), http.StatusBadRequest)
+		return
+	}
+	if from != 0 {
+		if data.From, ok = findRevision(revs, from); !ok {
+			http.Error(w, fmt.Sprintf("版本 %d 不存在", from), http.StatusBadRequest)
+			return
+		}
+	}
+	data.Lines, data.Exact = lineDiff(splitLines(data.From.Body), splitLines(data.To.Body))
+	renderStatus(w, http.StatusOK, "diff", data)
+}
+
+// revertHandler 把页面回退到表单中 rev 指定的版本。回退本身作为一个新版本保存，之后的版本仍然保留在历史中
+func (wk *wiki) revertHandler(w http.ResponseWriter, r *http.Request, title string) {
+	if !parsePostForm(w, r) {
+		return
+	}
+	n, err := strconv.Atoi(r.FormValue("rev"))
+	if err != nil {
+		http.Error(w, "rev 不是合法的版本号", http.StatusBadRequest)
+		return
+	}
+	revs, err := wk.pageHistory(title)
+	if err != nil {
+		log.Printf("读取页面 %s 的历史失败: %v", title, err)
+		http.Error(w, "读取历史失败", http.StatusInternalServerError)
+		return
+	}
+	rev, ok := findRevision(revs, n)
+	if !ok {
+		http.Error(w, fmt.Sprintf("版本 %d 不存在", n), http.StatusBadRequest)
+		return
+	}
+	message := fmt.Sprintf("回退到版本 %d", n)
+	if m := strings.TrimSpace(r.FormValue("message")); m != "" {
+		message += ": " + m
+	}
+	if err := wk.savePage(title, rev.Body, r.FormValue("author"), message); err != nil {
+		log.Printf("回退页面 %s 失败: %v", title, err)
+		http.Error(w, "回退页面失败", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/history/"+title, http.StatusFound)
+}
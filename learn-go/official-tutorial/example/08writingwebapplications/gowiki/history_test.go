@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// save 通过编辑表单保存一个版本
+func save(t *testing.T, h http.Handler, title, body, author, message string) {
+	t.Helper()
+	rec := do(h, http.MethodPost, "/save/"+title, url.Values{"body": {body}, "author": {author}, "message": {message}})
+	if rec.Code != http.StatusFound {
+		t.Fatalf("save: %d\n%s", rec.Code, rec.Body)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	wk, h := newTestWiki(t)
+	save(t, h, "Page", "第一行\n第二行", "张三", "创建")
+	save(t, h, "Page", "第一行\n第二行", "李四", "没有修改")
+	save(t, h, "Page", "第一行\n修改后的第二行\n第三行", "", "")
+
+	revs, err := loadHistory(wk.dir, "Page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 2 {
+		t.Fatalf("内容没有变化时不应当产生新版本，got %d 个版本", len(revs))
+	}
+	if r := revs[0]; r.Rev != 1 || r.Author != "张三" || r.Message != "创建" || r.Time.IsZero() {
+		t.Errorf("版本 1 = %+v", r)
+	}
+	if r := revs[1]; r.Rev != 2 || r.Author != anonymous || r.Time.Before(revs[0].Time) {
+		t.Errorf("版本 2 = %+v", r)
+	}
+
+	rec := do(h, http.MethodGet, "/history/Page", nil)
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || strings.Index(body, "?rev=2") > strings.Index(body, "?rev=1") {
+		t.Fatalf("history 应当按新到旧排列: %d\n%s", rec.Code, body)
+	}
+	for _, want := range []string{"张三", "创建", anonymous, `/diff/Page?from=1&to=2`, `name="rev" value="1"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("history 中没有 %q", want)
+		}
+	}
+	if strings.Contains(body, `name="rev" value="2"`) {
+		t.Error("最新版本不应当有回退按钮")
+	}
+
+	rec = do(h, http.MethodGet, "/view/Page?rev=1", nil)
+	if !strings.Contains(rec.Body.String(), "这是版本 1") || strings.Contains(rec.Body.String(), "第三行") {
+		t.Errorf("view?rev=1:\n%s", rec.Body)
+	}
+	if rec := do(h, http.MethodGet, "/view/Page?rev=9", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("不存在的版本: %d, want 404", rec.Code)
+	}
+	if rec := do(h, http.MethodGet, "/history/Missing", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("没有历史的页面: %d, want 404", rec.Code)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	_, h := newTestWiki(t)
+	save(t, h, "Page", "a\nb\nc", "张三", "")
+	save(t, h, "Page", "a\nB\nc\n<script>", "李四", "改了")
+
+	// 默认比较最新版本和上一版本
+	body := do(h, http.MethodGet, "/diff/Page", nil).Body.String()
+	for _, want := range []string{
+		`<span class="eq">  a</span>`,
+		`<span class="del">- b</span>`,
+		`<span class="ins">&#43; B</span>`,
+		`<span class="ins">&#43; &lt;script&gt;</span>`,
+		"版本 1 → 2",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("diff 中没有 %q:\n%s", want, body)
+		}
+	}
+
+	// from=0 与空白页面比较
+	body = do(h, http.MethodGet, "/diff/Page?from=0&to=1", nil).Body.String()
+	if !strings.Contains(body, `<span class="ins">&#43; a</span>`) || strings.Contains(body, `class="del"`) {
+		t.Errorf("from=0:\n%s", body)
+	}
+
+	for _, query := range []string{"?to=3", "?from=x", "?from=-1&to=2", "?to=0"} {
+		if rec := do(h, http.MethodGet, "/diff/Page"+query, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestRevert(t *testing.T) {
+	wk, h := newTestWiki(t)
+	save(t, h, "Page", "v1", "张三", "")
+	save(t, h, "Page", "v2", "张三", "")
+
+	rec := do(h, http.MethodPost, "/revert/Page", url.Values{"rev": {"1"}, "author": {"李四"}, "message": {"v2 有误"}})
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/history/Page" {
+		t.Fatalf("revert: %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if p, err := loadPage(wk.dir, "Page"); err != nil || string(p.Body) != "v1" {
+		t.Fatalf("回退后的内容 %q, %v", p.Body, err)
+	}
+	revs, _ := loadHistory(wk.dir, "Page")
+	if len(revs) != 3 || revs[2].Body != "v1" || revs[2].Author != "李四" || revs[2].Message != "回退到版本 1: v2 有误" {
+		t.Errorf("回退应当追加一个新版本: %+v", revs)
+	}
+
+	if rec := do(h, http.MethodGet, "/revert/Page", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /revert/: %d, want 405", rec.Code)
+	}
+	for _, rev := range []string{"", "x", "0", "4"} {
+		if rec := do(h, http.MethodPost, "/revert/Page", url.Values{"rev": {rev}}); rec.Code != http.StatusBadRequest {
+			t.Errorf("rev=%q: %d, want 400", rev, rec.Code)
+		}
+	}
+}
+
+// TestHistoryImportsExistingPage 有历史功能之前保存的页面，第一次保存时原有内容记为第 1 版
+func TestHistoryImportsExistingPage(t *testing.T) {
+	wk, h := newTestWiki(t)
+	if err := os.WriteFile(filepath.Join(wk.dir, "Old.txt"), []byte("旧内容"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// 保存之前历史页面同样显示隐含的第 1 版
+	rec := do(h, http.MethodGet, "/history/Old", nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "启用历史之前的内容") {
+		t.Fatalf("history: %d\n%s", rec.Code, rec.Body)
+	}
+	save(t, h, "Old", "新内容", "张三", "")
+	revs, err := loadHistory(wk.dir, "Old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 2 || revs[0].Body != "旧内容" || revs[1].Body != "新内容" || revs[1].Rev != 2 {
+		t.Errorf("revs = %+v", revs)
+	}
+}
+
+// TestSavePageKeepsHistoryInSync 追加历史失败时恢复页面原来的内容，之后重新保存相同的内容仍会记录新版本
+func TestSavePageKeepsHistoryInSync(t *testing.T) {
+	wk, _ := newTestWiki(t)
+	if err := os.WriteFile(filepath.Join(wk.dir, "Old.txt"), []byte("旧内容"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// 指向不存在目录的符号链接：读取时视为没有历史，追加时无法创建文件
+	broken := filepath.Join(wk.dir, "missing", "history")
+	for _, title := range []string{"Old", "New"} {
+		if err := os.Symlink(broken, historyFile(wk.dir, title)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := wk.savePage("Old", "新内容", "", ""); err == nil {
+		t.Fatal("追加历史失败时 savePage 应当返回错误")
+	}
+	if p, err := loadPage(wk.dir, "Old"); err != nil || string(p.Body) != "旧内容" {
+		t.Errorf("页面没有恢复: %q, %v", p.Body, err)
+	}
+	if err := wk.savePage("New", "内容", "", ""); err == nil {
+		t.Fatal("追加历史失败时 savePage 应当返回错误")
+	}
+	if _, err := os.Stat(pageFile(wk.dir, "New")); !os.IsNotExist(err) {
+		t.Errorf("新页面没有删除: %v", err)
+	}
+
+	os.Remove(historyFile(wk.dir, "Old"))
+	if err := wk.savePage("Old", "新内容", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if revs, _ := loadHistory(wk.dir, "Old"); len(revs) != 2 || revs[1].Body != "新内容" {
+		t.Errorf("revs = %+v", revs)
+	}
+}
+
+func TestConcurrentSaveAndRead(t *testing.T) {
+	wk, h := newTestWiki(t)
+	save(t, h, "Page", "v0", "", "")
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := wk.savePage("Page", fmt.Sprintf("v%d", i), "", ""); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			// 读取时不能看到追加了一半的历史
+			for _, path := range []string{"/history/Page", "/diff/Page", "/view/Page?rev=1"} {
+				if rec := do(h, http.MethodGet, path, nil); rec.Code != http.StatusOK {
+					t.Errorf("%s: %d\n%s", path, rec.Code, rec.Body)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if revs, err := loadHistory(wk.dir, "Page"); err != nil || len(revs) != 11 {
+		t.Errorf("got %d 个版本, %v, want 11", len(revs), err)
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"html"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 页面内容按 Markdown 的一个子集渲染：标题、段落、强调、行内代码、代码块、
+// 无序和有序列表、引用、分隔线、[文字](链接) 以及指向其他页面的 [[标题]]。
+//
+// 渲染是白名单式的：源文本中的 HTML 先全部转义，之后只有 allowedTags 中
+// 不带任何属性的标签被还原，并在每个块结束时补齐未闭合的标签；链接只允许
+// allowedSchemes 中的协议和相对地址，其他链接只保留文字。
+
+// allowedTags 允许在页面中直接书写的 HTML 标签，均不能带属性
+var allowedTags = map[string]bool{
+	"b": true, "i": true, "u": true, "s": true, "em": true, "strong": true,
+	"sub": true, "sup": true, "kbd": true, "mark": true, "del": true, "ins": true,
+	"small": true, "br": true,
+}
+
+// voidTags 没有结束标签的元素
+var voidTags = map[string]bool{"br": true}
+
+// allowedSchemes 链接允许的协议，没有协议的相对地址同样允许
+var allowedSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+var (
+	headingLine = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	ruleLine    = regexp.MustCompile(`^(\*\s*\*\s*\*[\s*]*|-\s*-\s*-[\s-]*|_\s*_\s*_[\s_]*)$`)
+	bulletLine  = regexp.MustCompile(`^\s{0,3}[-*+]\s+(.*)$`)
+	orderedLine = regexp.MustCompile(`^\s{0,3}(\d{1,9})[.)]\s+(.*)$`)
+	quoteLine   = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	fenceLine   = regexp.MustCompile("^\\s{0,3}```")
+
+	// inlineAtom 不参与强调处理的行内元素：`代码`、[[页面]]、[文字](链接)
+	inlineAtom = regexp.MustCompile("`([^`]+)`|\\[\\[([^\\]]+)\\]\\]|\\[([^\\]]+)\\]\\(([^)\\s]+)\\)")
+	strongText = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`)
+	emText     = regexp.MustCompile(`\*(\S(?:.*?\S)?)\*`)
+	// escapedTag 转义后的标签，如 &lt;b&gt;、&lt;/b&gt;、&lt;br/&gt;
+	escapedTag = regexp.MustCompile(`(?i)&lt;(/?)([a-z]+)\s*/?&gt;`)
+	// wikiTitle 合法的页面标题，与 validPath 一致
+	wikiTitle = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+)
+
+// renderMarkdown 把页面内容渲染为可以直接输出的 HTML
+func renderMarkdown(src []byte) template.HTML {
+	text := strings.ReplaceAll(string(src), "\r\n", "\n")
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(text, "\n"))
+	return template.HTML(b.String())
+}
+
+// renderBlocks 渲染一组行，引用中的内容递归调用
+func renderBlocks(b *strings.Builder, lines []string) {
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			b.WriteString("<p>" + renderInline(strings.Join(para, "\n")) + "</p>\n")
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+
+		case fenceLine.MatchString(line):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !fenceLine.MatchString(lines[i]); i++ {
+				code = append(code, lines[i])
+			}
+			b.WriteString("<pre><code>" + escapeCode(strings.Join(code, "\n")) + "</code></pre>\n")
+
+		case headingLine.MatchString(line):
+			flush()
+			m := headingLine.FindStringSubmatch(line)
+			level := strconv.Itoa(len(m[1]))
+			b.WriteString("<h" + level + ">" + renderInline(m[2]) + "</h" + level + ">\n")
+
+		case ruleLine.MatchString(strings.TrimSpace(line)):
+			flush()
+			b.WriteString("<hr>\n")
+
+		case quoteLine.MatchString(line):
+			flush()
+			var quoted []string
+			for ; i < len(lines) && quoteLine.MatchString(lines[i]); i++ {
+				quoted = append(quoted, quoteLine.FindStringSubmatch(lines[i])[1])
+			}
+			i--
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quoted)
+			b.WriteString("</blockquote>\n")
+
+		case bulletLine.MatchString(line), orderedLine.MatchString(line):
+			flush()
+			item, tag := bulletLine, "ul"
+			if !bulletLine.MatchString(line) {
+				item, tag = orderedLine, "ol"
+			}
+			b.WriteString("<" + tag + ">\n")
+			for i < len(lines) && item.MatchString(lines[i]) {
+				m := item.FindStringSubmatch(lines[i])
+				content := m[len(m)-1]
+				// 缩进的后续行属于同一个列表项
+				for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "" &&
+					(strings.HasPrefix(lines[i], "  ") || strings.HasPrefix(lines[i], "\t")); i++ {
+					content += "\n" + strings.TrimSpace(lines[i])
+				}
+				b.WriteString("<li>" + renderInline(content) + "</li>\n")
+			}
+			i--
+			b.WriteString("</" + tag + ">\n")
+
+		default:
+			para = append(para, line)
+		}
+	}
+	flush()
+}
+
+// renderInline 渲染一个块内的行内元素，并还原白名单中的标签。
+// 只有文字片段经过 sanitizeTags，链接地址等属性值保持转义，不会被还原出标签
+func renderInline(s string) string {
+	var b strings.Builder
+	var tags tagSanitizer
+	for s != "" {
+		loc := inlineAtom.FindStringSubmatchIndex(s)
+		if loc == nil {
+			b.WriteString(tags.sanitize(renderText(s)))
+			break
+		}
+		b.WriteString(tags.sanitize(renderText(s[:loc[0]])))
+		group := func(n int) string {
+			if loc[2*n] < 0 {
+				return ""
+			}
+			return s[loc[2*n]:loc[2*n+1]]
+		}
+		switch {
+		case loc[2] >= 0: // `代码`
+			b.WriteString("<code>" + escapeCode(group(1)) + "</code>")
+		case loc[4] >= 0: // [[页面]]
+			title := strings.TrimSpace(group(2))
+			if wikiTitle.MatchString(title) {
+				b.WriteString(`<a href="/view/` + title + `">` + title + "</a>")
+			} else {
+				b.WriteString(tags.sanitize(renderText(s[loc[0]:loc[1]])))
+			}
+		default: // [文字](链接)
+			text, link := group(3), group(4)
+			if safeURL(link) {
+				// 链接文字中的标签在 </a> 之前闭合，不会跨出链接
+				b.WriteString(`<a href="` + html.EscapeString(link) + `" rel="nofollow">` + sanitizeTags(renderText(text)) + "</a>")
+			} else {
+				b.WriteString(tags.sanitize(renderText(text)))
+			}
+		}
+		s = s[loc[1]:]
+	}
+	b.WriteString(tags.close())
+	return b.String()
+}
+
+// renderText 转义普通文字并处理 **加粗**、*强调* 和行尾两个空格的换行
+func renderText(s string) string {
+	s = html.EscapeString(s)
+	s = strongText.ReplaceAllString(s, "<strong>$1</strong>")
+	s = emText.ReplaceAllString(s, "<em>$1</em>")
+	return strings.ReplaceAll(s, "  \n", "<br>\n")
+}
+
+// escapeCode 转义代码中的文字。< 和 > 使用数字实体，sanitizeTags 不会把代码中的 <b> 还原为标签
+func escapeCode(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&#60;", ">", "&#62;", `"`, "&#34;", "'", "&#39;").Replace(s)
+}
+
+// sanitizeTags 把转义后的白名单标签还原为 HTML，并在末尾闭合仍未闭合的标签
+func sanitizeTags(s string) string {
+	var tags tagSanitizer
+	return tags.sanitize(s) + tags.close()
+}
+
+// tagSanitizer 依次处理同一个块中的文字片段，记录跨片段仍未闭合的标签
+type tagSanitizer struct {
+	open []string
+}
+
+// sanitize 把转义后的白名单标签还原为 HTML，丢弃没有对应开始标签的结束标签，其他标签保持转义。
+// s 必须是已经转义的文字，不能包含属性值
+func (t *tagSanitizer) sanitize(s string) string {
+	return escapedTag.ReplaceAllStringFunc(s, func(tag string) string {
+		m := escapedTag.FindStringSubmatch(tag)
+		closing, name := m[1] == "/", strings.ToLower(m[2])
+		switch {
+		case !allowedTags[name]:
+			return tag
+		case voidTags[name]:
+			return "<" + name + ">"
+		case !closing:
+			t.open = append(t.open, name)
+			return "<" + name + ">"
+		}
+		// 结束标签依次闭合到与之对应的开始标签，找不到开始标签时丢弃
+		for i := len(t.open) - 1; i >= 0; i-- {
+			if t.open[i] == name {
+				var closed strings.Builder
+				for j := len(t.open) - 1; j >= i; j-- {
+					closed.WriteString("</" + t.open[j] + ">")
+				}
+				t.open = t.open[:i]
+				return closed.String()
+			}
+		}
+		return ""
+	})
+}
+
+// close 闭合仍未闭合的标签，避免影响块之外的内容
+func (t *tagSanitizer) close() string {
+	var b strings.Builder
+	for i := len(t.open) - 1; i >= 0; i-- {
+		b.WriteString("</" + t.open[i] + ">")
+	}
+	t.open = nil
+	return b.String()
+}
+
+// safeURL 判断链接地址是否允许：相对地址或 allowedSchemes 中的协议，
+// javascript:、data: 等协议和无法解析的地址一律不允许。
+// //evil.com 这样省略协议的地址会跳转到其他站点，不算相对地址；浏览器把 \ 当作 /，同样不允许
+func safeURL(link string) bool {
+	if strings.Contains(link, `\`) {
+		return false
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" {
+		return u.Host == "" && !strings.HasPrefix(link, "//")
+	}
+	return allowedSchemes[u.Scheme]
+}
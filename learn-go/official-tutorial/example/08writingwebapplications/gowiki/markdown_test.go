@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"段落", "第一行\n第二行\n\n第二段", "<p>第一行\n第二行</p>\n<p>第二段</p>\n"},
+		{"标题", "# 标题 #\n### 三级", "<h1>标题</h1>\n<h3>三级</h3>\n"},
+		{"强调", "**粗** 和 *斜* 和 2 * 3 * 4", "<p><strong>粗</strong> 和 <em>斜</em> 和 2 * 3 * 4</p>\n"},
+		{"行内代码不处理强调和标签", "`**a** <b>`", "<p><code>**a** &#60;b&#62;</code></p>\n"},
+		{"代码块", "```\n<script>\n**x**\n```", "<pre><code>&#60;script&#62;\n**x**</code></pre>\n"},
+		{"无序列表", "- 一\n  续行\n* 二\n\n1. 甲\n2) 乙", "<ul>\n<li>一\n续行</li>\n<li>二</li>\n</ul>\n<ol>\n<li>甲</li>\n<li>乙</li>\n</ol>\n"},
+		{"引用", "> # 标题\n> 内容", "<blockquote>\n<h1>标题</h1>\n<p>内容</p>\n</blockquote>\n"},
+		{"分隔线", "上\n\n---\n\n下", "<p>上</p>\n<hr>\n<p>下</p>\n"},
+		{"换行", "行尾两个空格  \n下一行", "<p>行尾两个空格<br>\n下一行</p>\n"},
+		{"链接", "[Go](https://go.dev/?a=1&b=2) [相对](/view/Go) [邮件](mailto:a@b.c)",
+			`<p><a href="https://go.dev/?a=1&amp;b=2" rel="nofollow">Go</a> <a href="/view/Go" rel="nofollow">相对</a> <a href="mailto:a@b.c" rel="nofollow">邮件</a></p>` + "\n"},
+		{"页面链接", "见 [[FrontPage]] 和 [[../etc]]", `<p>见 <a href="/view/FrontPage">FrontPage</a> 和 [[../etc]]</p>` + "\n"},
+	}
+	for _, tt := range tests {
+		if got := string(renderMarkdown([]byte(tt.src))); got != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestRenderMarkdownSanitize 检查白名单之外的 HTML 和链接不会原样输出
+func TestRenderMarkdownSanitize(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"script", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{"带属性的白名单标签", `<b onclick="alert(1)">x</b>`, "<p>&lt;b onclick=&#34;alert(1)&#34;&gt;x</p>\n"},
+		{"img", `<img src=x onerror=alert(1)>`, "<p>&lt;img src=x onerror=alert(1)&gt;</p>\n"},
+		{"白名单标签", "<B>粗</b> H<sub>2</sub>O<br/>", "<p><b>粗</b> H<sub>2</sub>O<br></p>\n"},
+		{"未闭合的标签在块末尾闭合", "<b><i>x\n\n段落", "<p><b><i>x</i></b></p>\n<p>段落</p>\n"},
+		{"交错的结束标签", "<b><i>x</b>y</i>", "<p><b><i>x</i></b>y</p>\n"},
+		{"javascript 链接", "[点我](javascript:alert(1))", "<p>点我)</p>\n"},
+		{"大小写混合的协议", "[点我](JavaScript:alert`1`)", "<p>点我</p>\n"},
+		{"data 链接", "[x](data:text/html;base64,PHNjcmlwdD4=)", "<p>x</p>\n"},
+		{"链接中的引号", `[x](/a"onmouseover="alert(1))`, `<p><a href="/a&#34;onmouseover=&#34;alert(1" rel="nofollow">x</a>)</p>` + "\n"},
+		{"链接地址中的标签保持转义", "[x](http://a/<b>x)", `<p><a href="http://a/&lt;b&gt;x" rel="nofollow">x</a></p>` + "\n"},
+		{"链接文字中的标签在链接内闭合", "[<b>x](/a) y</b>", `<p><a href="/a" rel="nofollow"><b>x</b></a> y</p>` + "\n"},
+		{"标签跨过链接", "<i>a [x](/a) b</i>", `<p><i>a <a href="/a" rel="nofollow">x</a> b</i></p>` + "\n"},
+		{"省略协议的链接", "[x](//evil.com/a)", "<p>x</p>\n"},
+		{"反斜杠", `[x](/\evil.com)`, "<p>x</p>\n"},
+	}
+	for _, tt := range tests {
+		got := string(renderMarkdown([]byte(tt.src)))
+		if got != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.name, got, tt.want)
+		}
+		if strings.Contains(strings.ToLower(got), "<script") || strings.Contains(got, "javascript:") {
+			t.Errorf("%s: 输出中含有脚本: %q", tt.name, got)
+		}
+	}
+}
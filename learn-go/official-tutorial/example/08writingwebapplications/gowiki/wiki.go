@@ -1,43 +1,242 @@
 package main
 
 import (
+	"bytes"
+	"embed"
+	"errors"
+	"flag"
 	"fmt"
+	"html/template"
+	"io/fs"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
 )
 
+//go:embed *.html
+var templateFS embed.FS
+
+// templates 启动时解析一次全部模板，之后每次请求直接执行，不再读取和解析模板文件。
+// html/template 对模板中的数据自动转义，页面内容通过 markdown 函数渲染为经过白名单过滤的 HTML
+var templates = template.Must(template.New("").
+	Funcs(template.FuncMap{
+		"markdown": renderMarkdown,
+		"sub":      func(a, b int) int { return a - b },
+	}).
+	ParseFS(templateFS, "*.html"))
+
+// validPath 匹配 /edit/、/save/、/view/、/history/、/diff/、/revert/ 加页面标题的路径。
+// 标题只允许字母和数字，用作文件名时不会出现 ../ 之类的路径穿越
+var validPath = regexp.MustCompile("^/(edit|save|view|history|diff|revert)/([a-zA-Z0-9]+)$")
+
+// frontPage 访问根路径时跳转到的页面
+const frontPage = "FrontPage"
+
+// maxFormSize 保存和回退表单的最大字节数，同时限制了单个页面的大小
+const maxFormSize = 1 << 20
+
 type Page struct {
 	Title string
 	Body  []byte
+	// Rev 查看历史版本时为版本号，查看当前内容时为 0
+	Rev int
+}
+
+// pageFile 返回页面在数据目录 dir 中的文件名
+func pageFile(dir, title string) string {
+	return filepath.Join(dir, title+".txt")
 }
 
-func (p *Page) save() error {
-	filename := p.Title + ".txt"
-	return os.WriteFile(filename, p.Body, 0600)
+func (p *Page) save(dir string) error {
+	return os.WriteFile(pageFile(dir, p.Title), p.Body, 0600)
 }
 
-func loadPage(title string) (*Page, error) {
-	filename := title + ".txt"
-	body, err := os.ReadFile(filename)
+func loadPage(dir, title string) (*Page, error) {
+	body, err := os.ReadFile(pageFile(dir, title))
 	if err != nil {
 		return nil, err
 	}
 	return &Page{Title: title, Body: body}, nil
 }
 
-func viewHandler(w http.ResponseWriter, r *http.Request) {
-	title := r.URL.Path[len("/view/"):]
-	p, _ := loadPage(title)
-	fmt.Fprintf(w, "<h1>%s</h1><div>%s</div>", p.Title, p.Body)
+// wiki 保存在 dir 目录中的 wiki，每个页面一个保存当前内容的 .txt 文件和一个记录全部版本的 .history.jsonl 文件
+type wiki struct {
+	dir string
+	// mu 保证同一时间只有一次保存，版本号不会重复；读取页面和历史时持有读锁，不会读到写了一半的文件
+	mu sync.RWMutex
+}
+
+// loadPage 在读锁下读取页面的当前内容
+func (wk *wiki) loadPage(title string) (*Page, error) {
+	wk.mu.RLock()
+	defer wk.mu.RUnlock()
+	return loadPage(wk.dir, title)
+}
+
+// pageHistory 在读锁下读取页面的全部版本
+func (wk *wiki) pageHistory(title string) ([]Revision, error) {
+	wk.mu.RLock()
+	defer wk.mu.RUnlock()
+	return pageHistory(wk.dir, title)
+}
+
+// renderTemplate 以状态码 200 渲染模板
+func renderTemplate(w http.ResponseWriter, tmpl string, p *Page) {
+	renderStatus(w, http.StatusOK, tmpl, p)
+}
+
+// renderStatus 先把模板执行到缓冲区，出错时返回 500，避免把渲染了一半的页面发给客户端
+func renderStatus(w http.ResponseWriter, code int, tmpl string, data any) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, tmpl+".html", data); err != nil {
+		log.Printf("渲染 %s 失败: %v", tmpl, err)
+		http.Error(w, "渲染页面失败", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// 页面内容来自用户，禁止执行任何脚本作为转义之外的第二道防线
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'none'; object-src 'none'; style-src 'self' 'unsafe-inline'")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	buf.WriteTo(w)
+}
+
+// notFound 返回 404 页面，带有回到首页的链接
+func notFound(w http.ResponseWriter, r *http.Request) {
+	renderStatus(w, http.StatusNotFound, "notfound", r.URL.Path)
+}
+
+// viewHandler 显示页面，页面不存在时跳转到编辑页创建；带有 ?rev=N 时显示第 N 版
+func (wk *wiki) viewHandler(w http.ResponseWriter, r *http.Request, title string) {
+	if v := r.FormValue("rev"); v != "" {
+		wk.viewRevision(w, r, title, v)
+		return
+	}
+	p, err := wk.loadPage(title)
+	if errors.Is(err, fs.ErrNotExist) {
+		http.Redirect(w, r, "/edit/"+title, http.StatusFound)
+		return
+	}
+	if err != nil {
+		log.Printf("读取页面 %s 失败: %v", title, err)
+		http.Error(w, "读取页面失败", http.StatusInternalServerError)
+		return
+	}
+	renderTemplate(w, "view", p)
+}
+
+// viewRevision 显示页面的一个历史版本，版本不存在时返回 404 页面
+func (wk *wiki) viewRevision(w http.ResponseWriter, r *http.Request, title, v string) {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		http.Error(w, "rev 不是合法的版本号", http.StatusBadRequest)
+		return
+	}
+	revs, err := wk.pageHistory(title)
+	if err != nil {
+		log.Printf("读取页面 %s 的历史失败: %v", title, err)
+		http.Error(w, "读取历史失败", http.StatusInternalServerError)
+		return
+	}
+	rev, ok := findRevision(revs, n)
+	if !ok {
+		notFound(w, r)
+		return
+	}
+	renderTemplate(w, "view", &Page{Title: title, Body: []byte(rev.Body), Rev: rev.Rev})
+}
+
+// editHandler 显示编辑表单，页面不存在时表单为空
+func (wk *wiki) editHandler(w http.ResponseWriter, r *http.Request, title string) {
+	p, err := wk.loadPage(title)
+	if errors.Is(err, fs.ErrNotExist) {
+		p = &Page{Title: title}
+	} else if err != nil {
+		log.Printf("读取页面 %s 失败: %v", title, err)
+		http.Error(w, "读取页面失败", http.StatusInternalServerError)
+		return
+	}
+	renderTemplate(w, "edit", p)
+}
+
+// parsePostForm 检查请求方法为 POST，并限制请求体不超过 maxFormSize 后解析表单，失败时返回错误响应
+func parsePostForm(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "只支持 POST", http.StatusMethodNotAllowed)
+		return false
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxFormSize)
+	if err := r.ParseForm(); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("页面内容不能超过 %d 字节", maxFormSize), http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(w, "表单格式不正确", http.StatusBadRequest)
+		}
+		return false
+	}
+	return true
+}
+
+// saveHandler 把编辑表单提交的内容连同作者和修改说明保存为新版本，然后跳转到页面
+func (wk *wiki) saveHandler(w http.ResponseWriter, r *http.Request, title string) {
+	if !parsePostForm(w, r) {
+		return
+	}
+	if err := wk.savePage(title, r.FormValue("body"), r.FormValue("author"), r.FormValue("message")); err != nil {
+		log.Printf("保存页面 %s 失败: %v", title, err)
+		http.Error(w, "保存页面失败", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/view/"+title, http.StatusFound)
+}
+
+// makeHandler 校验路径中的页面标题，合法时把标题交给 fn，否则返回 404 页面
+func makeHandler(fn func(http.ResponseWriter, *http.Request, string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m := validPath.FindStringSubmatch(r.URL.Path)
+		if m == nil {
+			notFound(w, r)
+			return
+		}
+		fn(w, r, m[2])
+	}
+}
+
+// handler 返回 wiki 的全部路由
+func (wk *wiki) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/view/", makeHandler(wk.viewHandler))
+	mux.HandleFunc("/edit/", makeHandler(wk.editHandler))
+	mux.HandleFunc("/save/", makeHandler(wk.saveHandler))
+	mux.HandleFunc("/history/", makeHandler(wk.historyHandler))
+	mux.HandleFunc("/diff/", makeHandler(wk.diffHandler))
+	mux.HandleFunc("/revert/", makeHandler(wk.revertHandler))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			notFound(w, r)
+			return
+		}
+		http.Redirect(w, r, "/view/"+frontPage, http.StatusFound)
+	})
+	return mux
 }
 
 func main() {
-	p1 := &Page{Title: "TestPage", Body: []byte("This is a sample Page.")}
-	p1.save()
-	p2, _ := loadPage("TestPage")
-	fmt.Println(string(p2.Body))
+	addr := flag.String("addr", ":8088", "HTTP 监听地址")
+	dataDir := flag.String("data", "data", "保存页面的目录，不存在时自动创建")
+	flag.Parse()
+
+	if err := os.MkdirAll(*dataDir, 0700); err != nil {
+		log.Fatalf("创建数据目录失败: %v", err)
+	}
+	wk := &wiki{dir: *dataDir}
 
-	http.HandleFunc("/view/", viewHandler)
-	log.Fatal(http.ListenAndServe(":8088", nil))
+	log.Printf("wiki 运行中: http://localhost%s/，页面保存在 %s", *addr, *dataDir)
+	log.Fatal(http.ListenAndServe(*addr, wk.handler()))
 }
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestWiki(t *testing.T) (*wiki, http.Handler) {
+	t.Helper()
+	wk := &wiki{dir: t.TempDir()}
+	return wk, wk.handler()
+}
+
+// do 发送请求并返回响应，不跟随跳转
+func do(h http.Handler, method, target string, form url.Values) *httptest.ResponseRecorder {
+	var body *strings.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	} else {
+		body = strings.NewReader("")
+	}
+	req := httptest.NewRequest(method, target, body)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestEditSaveView(t *testing.T) {
+	wk, h := newTestWiki(t)
+
+	// 页面不存在时 view 跳转到 edit，edit 显示空表单
+	rec := do(h, http.MethodGet, "/view/NewPage", nil)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/edit/NewPage" {
+		t.Fatalf("view 不存在的页面: %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	rec = do(h, http.MethodGet, "/edit/NewPage", nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `action="/save/NewPage"`) {
+		t.Fatalf("edit: %d\n%s", rec.Code, rec.Body)
+	}
+
+	// 保存后跳转到 view，内容写入数据目录
+	rec = do(h, http.MethodPost, "/save/NewPage", url.Values{"body": {"第一版内容"}})
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/view/NewPage" {
+		t.Fatalf("save: %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if data, err := os.ReadFile(filepath.Join(wk.dir, "NewPage.txt")); err != nil || string(data) != "第一版内容" {
+		t.Fatalf("文件内容 %q, %v", data, err)
+	}
+	rec = do(h, http.MethodGet, "/view/NewPage", nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "第一版内容") {
+		t.Fatalf("view: %d\n%s", rec.Code, rec.Body)
+	}
+	rec = do(h, http.MethodGet, "/edit/NewPage", nil)
+	if !strings.Contains(rec.Body.String(), ">第一版内容</textarea>") {
+		t.Errorf("edit 没有带出已有内容:\n%s", rec.Body)
+	}
+
+	if rec := do(h, http.MethodGet, "/save/NewPage", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /save/: %d, want 405", rec.Code)
+	}
+	if rec := do(h, http.MethodGet, "/", nil); rec.Header().Get("Location") != "/view/"+frontPage {
+		t.Errorf("根路径跳转到 %q", rec.Header().Get("Location"))
+	}
+}
+
+func TestSaveTooLarge(t *testing.T) {
+	wk, h := newTestWiki(t)
+	rec := do(h, http.MethodPost, "/save/Big", url.Values{"body": {strings.Repeat("x", maxFormSize)}})
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("save: %d, want 413", rec.Code)
+	}
+	if _, err := os.Stat(filepath.Join(wk.dir, "Big.txt")); !os.IsNotExist(err) {
+		t.Errorf("超过大小的页面被保存了: %v", err)
+	}
+}
+
+func TestInvalidTitle(t *testing.T) {
+	wk, h := newTestWiki(t)
+	for _, target := range []string{
+		"/view/",
+		"/view/a.b",
+		"/view/..%2fsecret",
+		"/edit/a/b",
+		"/save/%2e%2e",
+		"/view/页面",
+		"/other",
+	} {
+		if rec := do(h, http.MethodPost, target, url.Values{"body": {"x"}}); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: %d, want 404", target, rec.Code)
+		}
+	}
+	if entries, _ := os.ReadDir(wk.dir); len(entries) != 0 {
+		t.Errorf("不合法的标题写入了文件: %v", entries)
+	}
+}
+
+func TestViewEscapesPage(t *testing.T) {
+	_, h := newTestWiki(t)
+	body := "# 标题\n\n<script>alert(document.cookie)</script> **粗体** [x](javascript:alert(1))"
+	do(h, http.MethodPost, "/save/XSS", url.Values{"body": {body}})
+
+	rec := do(h, http.MethodGet, "/view/XSS", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("view: %d", rec.Code)
+	}
+	got := rec.Body.String()
+	for _, bad := range []string{"<script>alert", "javascript:"} {
+		if strings.Contains(got, bad) {
+			t.Errorf("页面中含有 %q:\n%s", bad, got)
+		}
+	}
+	for _, want := range []string{"<h1>标题</h1>", "&lt;script&gt;", "<strong>粗体</strong>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("页面中没有 %q:\n%s", want, got)
+		}
+	}
+	if csp := rec.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "script-src 'none'") {
+		t.Errorf("Content-Security-Policy = %q", csp)
+	}
+
+	// 编辑表单中的原文同样被转义，不会提前结束 textarea
+	do(h, http.MethodPost, "/save/XSS", url.Values{"body": {"</textarea><script>alert(1)</script>"}})
+	if got := do(h, http.MethodGet, "/edit/XSS", nil).Body.String(); strings.Contains(got, "<script>") {
+		t.Errorf("编辑页中含有脚本:\n%s", got)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	wk, h := newTestWiki(t)
+	rec := do(h, http.MethodGet, "/view/<script>", nil)
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "页面不存在") {
+		t.Fatalf("%d\n%s", rec.Code, rec.Body)
+	}
+	if strings.Contains(rec.Body.String(), "<script>") {
+		t.Errorf("404 页面中的路径没有转义:\n%s", rec.Body)
+	}
+
+	// 页面文件无法读取（这里是同名的目录）时返回 500，而不是崩溃
+	if err := os.Mkdir(filepath.Join(wk.dir, "Broken.txt"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for _, target := range []string{"/view/Broken", "/edit/Broken"} {
+		if rec := do(h, http.MethodGet, target, nil); rec.Code != http.StatusInternalServerError {
+			t.Errorf("%s: %d, want 500", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Package applylog 记录一次下发运行中每个应用的结果。
+//
+// web-api apply（zone-sync HTTP 接口）和 grpc 客户端（Resource.Apply）各自把结果写入记录文件，
+// web-api verify 只读取两份记录进行对比，不会再次调用任何下发接口。
+package applylog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Entry 单个应用的下发结果
+type Entry struct {
+	Zone  string `json:"zone"`
+	AppID int64  `json:"appId"`
+	OK    bool   `json:"ok"`
+	// Status HTTP 状态码（如 "200"）或 gRPC 状态码（如 "NotFound"），请求未拿到响应时为空
+	Status  string    `json:"status,omitempty"`
+	Message string    `json:"message,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// Write 把 entries 写入 path，先写临时文件再重命名，不会留下写了一半的记录
+func Write(path string, entries []Entry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("写入下发记录失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入下发记录失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入下发记录失败: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Read 读取 Write 写入的记录
+func Read(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取下发记录失败: %w", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("解析下发记录 %s 失败: %w", path, err)
+	}
+	return entries, nil
+}
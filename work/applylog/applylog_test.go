@@ -0,0 +1,35 @@
+package applylog
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apply-http.json")
+	want := []Entry{
+		{Zone: "cn", AppID: 12, OK: true, Status: "200", Message: "ok", Time: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Zone: "sg", AppID: 4, Status: "NotFound", Message: "app 4 not found"},
+	}
+	if err := Write(path, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read = %+v, want %+v", got, want)
+	}
+
+	// 覆盖写入时不留下临时文件
+	if err := Write(path, want[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := os.ReadDir(filepath.Dir(path)); len(files) != 1 {
+		t.Errorf("files = %v, want only %s", files, filepath.Base(path))
+	}
+}
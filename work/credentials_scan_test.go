@@ -0,0 +1,41 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+// basicAuthLiteral 匹配源码中硬编码的 Basic 认证串（"Basic " 后跟 base64 内容）
+var basicAuthLiteral = regexp.MustCompile(`Basic\s+[A-Za-z0-9+/]{12,}={0,2}`)
+
+// TestNoBasicAuthLiterals 扫描模块内所有 Go 源码，禁止再出现硬编码的 Basic 认证串
+func TestNoBasicAuthLiterals(t *testing.T) {
+	err := filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".go" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, loc := range basicAuthLiteral.FindAllIndex(data, -1) {
+			line := 1
+			for _, b := range data[:loc[0]] {
+				if b == '\n' {
+					line++
+				}
+			}
+			t.Errorf("%s:%d: 发现硬编码的 Basic 认证串，请改用环境变量或凭据文件", path, line)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
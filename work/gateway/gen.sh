@@ -0,0 +1,27 @@
+#!/bin/sh
+# 重新生成网关使用的代码和 OpenAPI 文档，需要 protoc、protoc-gen-go、protoc-gen-go-grpc、
+# protoc-gen-grpc-gateway、protoc-gen-openapiv2，以及 googleapis 中的 google/api/annotations.proto 和 http.proto。
+#
+# googleapis 不随仓库提供，通过环境变量 GOOGLEAPIS 指定其根目录，未设置时使用 work/third_party/googleapis：
+#   git clone --depth 1 https://github.com/googleapis/googleapis third_party/googleapis
+#   GOOGLEAPIS=/path/to/googleapis gateway/gen.sh
+set -e
+cd "$(dirname "$0")/.."
+GOOGLEAPIS=${GOOGLEAPIS:-third_party/googleapis}
+for f in google/api/annotations.proto google/api/http.proto; do
+	if [ ! -f "$GOOGLEAPIS/$f" ]; then
+		echo "gen.sh: 找不到 $GOOGLEAPIS/$f，请将 GOOGLEAPIS 设置为 googleapis 仓库的根目录" >&2
+		exit 1
+	fi
+done
+GOOGLEAPIS=$(cd "$GOOGLEAPIS" && pwd)
+
+(cd ../grpc-demo && protoc -I . -I "$GOOGLEAPIS" \
+	--go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. \
+	--grpc-gateway_out=paths=source_relative:. proto/greet.proto)
+(cd grpc/api && protoc -I . -I "$GOOGLEAPIS" \
+	--go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. \
+	--grpc-gateway_out=paths=source_relative:. resource.proto)
+protoc -I ../grpc-demo -I grpc/api -I "$GOOGLEAPIS" \
+	--openapiv2_out=allow_merge=true,merge_file_name=apidocs,json_names_for_fields=false:gateway \
+	proto/greet.proto resource.proto
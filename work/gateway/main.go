@@ -0,0 +1,97 @@
+// gateway 把 Greeter 和 Resource 两个 gRPC 服务以 REST/JSON 的形式提供给前端：
+//
+//	POST /v1/greet                      -> greet.Greeter/SayHello
+//	POST /v1/resources/{appId}:apply    -> resource.Resource/Apply
+//	GET  /openapi.json                  接口的 OpenAPI 文档
+//
+// 路由由 proto 中的 google.api.http 注解生成，OpenAPI 文档同样由 proto 生成（见 gen.sh）。
+package main
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"flag"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"grpc-demo/interceptor"
+	greet "grpc-demo/proto"
+	resource "learn-go/work/grpc/api"
+)
+
+//go:embed apidocs.swagger.json
+var openAPIDoc []byte
+
+func main() {
+	addr := flag.String("addr", ":8080", "HTTP 监听地址")
+	greeterAddr := flag.String("greeter-addr", "localhost:50051", "Greeter gRPC 服务地址")
+	resourceAddr := flag.String("resource-addr", "10.30.60.46:8848", "Resource gRPC 服务地址")
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	// 使用不安全连接，生产环境应使用TLS
+	dialOpts := append(interceptor.DialOptions(interceptor.Config{Logger: logger}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	greeterConn, err := grpc.NewClient(*greeterAddr, dialOpts...)
+	if err != nil {
+		log.Fatalf("连接 Greeter 失败: %v", err)
+	}
+	defer greeterConn.Close()
+	resourceConn, err := grpc.NewClient(*resourceAddr, dialOpts...)
+	if err != nil {
+		log.Fatalf("连接 Resource 失败: %v", err)
+	}
+	defer resourceConn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	handler, err := newGateway(ctx, greeterConn, resourceConn)
+	if err != nil {
+		log.Fatalf("注册网关路由失败: %v", err)
+	}
+
+	srv := &http.Server{Addr: *addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	log.Printf("网关运行中: %s (Greeter=%s, Resource=%s)", *addr, *greeterAddr, *resourceAddr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("服务失败: %v", err)
+	}
+}
+
+// newGateway 创建把 HTTP 请求转发到 greeterConn 和 resourceConn 的处理器
+// JSON 字段使用 proto 中的字段名（如 appId），与 OpenAPI 文档一致；请求中未知的字段会被忽略
+func newGateway(ctx context.Context, greeterConn, resourceConn *grpc.ClientConn) (http.Handler, error) {
+	mux := runtime.NewServeMux(runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+		MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+		UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+	}))
+	if err := greet.RegisterGreeterHandler(ctx, mux, greeterConn); err != nil {
+		return nil, err
+	}
+	if err := resource.RegisterResourceHandler(ctx, mux, resourceConn); err != nil {
+		return nil, err
+	}
+
+	root := http.NewServeMux()
+	root.Handle("/v1/", mux)
+	root.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPIDoc)
+	})
+	return root, nil
+}
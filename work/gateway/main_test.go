@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+	greet "grpc-demo/proto"
+	resource "learn-go/work/grpc/api"
+)
+
+type fakeGreeter struct {
+	greet.UnimplementedGreeterServer
+}
+
+func (fakeGreeter) SayHello(_ context.Context, req *greet.HelloRequest) (*greet.HelloReply, error) {
+	return &greet.HelloReply{Message: "你好, " + req.GetName() + "!"}, nil
+}
+
+// fakeResource 记录收到的应用 ID，0 号应用返回 NotFound
+type fakeResource struct {
+	resource.UnimplementedResourceServer
+	mu      sync.Mutex
+	applied []uint64
+}
+
+func (s *fakeResource) Apply(_ context.Context, req *resource.ApplyRequest) (*emptypb.Empty, error) {
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.NotFound, "app not found")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.applied = append(s.applied, req.GetAppId())
+	return &emptypb.Empty{}, nil
+}
+
+// dialBufconn 在内存连接上启动 s，返回连接到 s 的客户端连接
+func dialBufconn(t *testing.T, s *grpc.Server) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func newTestGateway(t *testing.T) (*httptest.Server, *fakeResource) {
+	t.Helper()
+	gs := grpc.NewServer()
+	greet.RegisterGreeterServer(gs, fakeGreeter{})
+	rs := grpc.NewServer()
+	res := &fakeResource{}
+	resource.RegisterResourceServer(rs, res)
+
+	handler, err := newGateway(t.Context(), dialBufconn(t, gs), dialBufconn(t, rs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv, res
+}
+
+func post(t *testing.T, url, body string) (int, string) {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(b)
+}
+
+func TestGreet(t *testing.T) {
+	srv, _ := newTestGateway(t)
+	code, body := post(t, srv.URL+"/v1/greet", `{"name": "张三", "unknown": 1}`)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", code, body)
+	}
+	var reply struct{ Message string }
+	if err := json.Unmarshal([]byte(body), &reply); err != nil || reply.Message != "你好, 张三!" {
+		t.Errorf("body = %s, %v", body, err)
+	}
+
+	if code, _ := post(t, srv.URL+"/v1/greet", `{"name": `); code != http.StatusBadRequest {
+		t.Errorf("非法 JSON: status = %d, want 400", code)
+	}
+}
+
+func TestApply(t *testing.T) {
+	srv, res := newTestGateway(t)
+	code, body := post(t, srv.URL+"/v1/resources/42:apply", `{}`)
+	if code != http.StatusOK || strings.TrimSpace(body) != "{}" {
+		t.Errorf("status = %d, body = %s", code, body)
+	}
+	res.mu.Lock()
+	applied := res.applied
+	res.mu.Unlock()
+	if len(applied) != 1 || applied[0] != 42 {
+		t.Errorf("applied = %v, want [42]", applied)
+	}
+
+	// gRPC 状态码映射为 HTTP 状态码，错误体为 google.rpc.Status
+	code, body = post(t, srv.URL+"/v1/resources/0:apply", `{}`)
+	if code != http.StatusNotFound || !strings.Contains(body, "app not found") {
+		t.Errorf("status = %d, body = %s", code, body)
+	}
+	if code, _ := post(t, srv.URL+"/v1/resources/abc:apply", `{}`); code != http.StatusBadRequest {
+		t.Errorf("非数字的 appId: status = %d, want 400", code)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	srv, _ := newTestGateway(t)
+	resp, err := http.Get(srv.URL + "/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var doc struct {
+		Swagger string
+		Paths   map[string]map[string]json.RawMessage
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/v1/greet", "/v1/resources/{appId}:apply"} {
+		if _, ok := doc.Paths[path]["post"]; !ok {
+			t.Errorf("OpenAPI 文档缺少 POST %s", path)
+		}
+	}
+}
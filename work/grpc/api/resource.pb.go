@@ -7,6 +7,7 @@
 package resource
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
@@ -23,8 +24,9 @@ const (
 )
 
 type ApplyRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AppId         uint64                 `protobuf:"varint,1,opt,name=appId,proto3" json:"appId,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 应用 ID
+	AppId         uint64 `protobuf:"varint,1,opt,name=appId,proto3" json:"appId,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -70,11 +72,11 @@ var File_resource_proto protoreflect.FileDescriptor
 
 const file_resource_proto_rawDesc = "" +
 	"\n" +
-	"\x0eresource.proto\x12\bresource\x1a\x1bgoogle/protobuf/empty.proto\"$\n" +
+	"\x0eresource.proto\x12\bresource\x1a\x1cgoogle/api/annotations.proto\x1a\x1bgoogle/protobuf/empty.proto\"$\n" +
 	"\fApplyRequest\x12\x14\n" +
-	"\x05appId\x18\x01 \x01(\x04R\x05appId2C\n" +
-	"\bResource\x127\n" +
-	"\x05Apply\x12\x16.resource.ApplyRequest\x1a\x16.google.protobuf.EmptyB\fZ\n" +
+	"\x05appId\x18\x01 \x01(\x04R\x05appId2k\n" +
+	"\bResource\x12_\n" +
+	"\x05Apply\x12\x16.resource.ApplyRequest\x1a\x16.google.protobuf.Empty\"&\x82\xd3\xe4\x93\x02 :\x01*\"\x1b/v1/resources/{appId}:applyB\fZ\n" +
 	".;resourceb\x06proto3"
 
 var (
@@ -27,8 +27,9 @@ const (
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 //
-// compile command : protoc  --go_out=. --go-grpc_out=. resource.proto
+// Resource 资源下发服务
 type ResourceClient interface {
+	// Apply 为应用下发资源，HTTP 网关映射为 POST /v1/resources/{appId}:apply
 	Apply(ctx context.Context, in *ApplyRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
@@ -54,8 +55,9 @@ func (c *resourceClient) Apply(ctx context.Context, in *ApplyRequest, opts ...gr
 // All implementations must embed UnimplementedResourceServer
 // for forward compatibility.
 //
-// compile command : protoc  --go_out=. --go-grpc_out=. resource.proto
+// Resource 资源下发服务
 type ResourceServer interface {
+	// Apply 为应用下发资源，HTTP 网关映射为 POST /v1/resources/{appId}:apply
 	Apply(context.Context, *ApplyRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedResourceServer()
 }
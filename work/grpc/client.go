@@ -2,22 +2,59 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
-	status2 "google.golang.org/grpc/status"
+	"grpc-demo/discovery"
+	"grpc-demo/interceptor"
+	"learn-go/work/applylog"
 	resource "learn-go/work/grpc/api"
+	"learn-go/work/grpc/record"
 	"log"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
 )
 
 func main() {
-	opts := []grpc.DialOption{
+	// 多个地址（逗号分隔、static:///a,b,c 或 file:///path）时按 round_robin 分发请求
+	target := flag.String("target", "10.30.60.46:8848", "Resource 服务地址")
+	zone := flag.String("zone", "cn", "target 所在的区域，写入下发记录")
+	apps := flag.String("apps", "", "逗号分隔的应用 ID，为空时使用代码中的列表")
+	recordFile := flag.String("record", "", "将每个应用的结果写入该文件，供 web-api verify 对比")
+	traceExporter := flag.String("trace-exporter", "none", "链路追踪导出方式: stdout、otlp、none")
+	otlpEndpoint := flag.String("otlp-endpoint", "localhost:4317", "trace-exporter 为 otlp 时 collector 的地址")
+	metricsAddr := flag.String("metrics-addr", "", "Prometheus /metrics 的 HTTP 监听地址，为空时只记录不提供")
+	flag.Parse()
+
+	tp, err := interceptor.NewTracerProvider(context.Background(), "resource-client", *traceExporter, *otlpEndpoint)
+	if err != nil {
+		log.Fatalf("初始化链路追踪失败: %v", err)
+	}
+	// 退出前导出剩余的 span
+	defer tp.Shutdown(context.Background())
+	cfg := interceptor.Config{Logger: slog.Default(), Metrics: interceptor.NewMetrics(), TracerProvider: tp}
+	if *metricsAddr != "" {
+		go func() {
+			log.Printf("指标服务运行中: http://%s/metrics", *metricsAddr)
+			if err := cfg.Metrics.ListenAndServe(*metricsAddr); err != nil {
+				log.Printf("指标服务失败: %v", err)
+			}
+		}()
+	}
+
+	// 记录每次 Apply 调用的 span、耗时指标和状态码
+	opts := append(interceptor.DialOptions(cfg),
 		// 使用不安全连接，生产环境应使用TLS
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	}
+	)
+	// 不确定服务端是否为 resource.Resource 单独设置了健康状态，只检查整体状态
+	opts = append(opts, discovery.DialOptions("")...)
 
 	// 建立gRPC连接
-	conn, err := grpc.Dial("10.30.60.46:8848", opts...)
+	conn, err := grpc.Dial(discovery.Target(*target), opts...)
 	if err != nil {
 		fmt.Println("did not connect:", err)
 		return
@@ -79,13 +116,39 @@ func main() {
 		//124,
 	}
 
+	if *apps != "" {
+		appIDs = nil
+		for _, v := range strings.Split(*apps, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				log.Fatalf("无效的应用 ID %q", v)
+			}
+			appIDs = append(appIDs, id)
+		}
+	}
+
+	// 单个应用失败不影响后续应用，全部处理完后再写入记录并退出
+	var entries []applylog.Entry
+	failed := 0
 	for _, id := range appIDs {
-		_, err = client.Apply(context.Background(), &resource.ApplyRequest{AppId: id})
-		if status, _ := status2.FromError(err); status != nil {
-			log.Fatalf("error: %v", status)
+		entry, err := record.Apply(context.Background(), client, *zone, id)
+		entries = append(entries, entry)
+		if err != nil {
+			failed++
+			log.Printf("error: %v", err)
 		} else {
 			fmt.Printf("success: %v\n", id)
 		}
 	}
-
+	if *recordFile != "" {
+		if err := applylog.Write(*recordFile, entries); err != nil {
+			failed++
+			log.Print(err)
+		}
+	}
+	if failed > 0 {
+		// os.Exit 不会执行 defer，先导出 span
+		tp.Shutdown(context.Background())
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,23 @@
+// Package record 调用 Resource.Apply 下发单个应用，并把结果转换为下发记录，
+// 供 grpc 客户端写入记录文件，以及 web-api verify 的测试使用同一份转换逻辑。
+package record
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/status"
+	"learn-go/work/applylog"
+	resource "learn-go/work/grpc/api"
+)
+
+// Apply 调用 Resource.Apply 下发应用 id，返回该应用的下发记录和调用错误。
+// 记录的 Status 为 gRPC 状态码，资源不存在时为 NotFound
+func Apply(ctx context.Context, client resource.ResourceClient, zone string, id uint64) (applylog.Entry, error) {
+	_, err := client.Apply(ctx, &resource.ApplyRequest{AppId: id})
+	st := status.Convert(err)
+	return applylog.Entry{
+		Zone: zone, AppID: int64(id), OK: err == nil,
+		Status: st.Code().String(), Message: st.Message(), Time: time.Now(),
+	}, err
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"learn-go/work/applylog"
+	"learn-go/work/zonesync"
+)
+
+// appResult 单个应用的下发结果，Status 为 0 表示请求未拿到 HTTP 响应
+type appResult struct {
+	Zone    string
+	AppID   int64
+	Status  int
+	Message string
+	Err     error
+}
+
+// ok 是否下发成功
+func (r appResult) ok() bool {
+	return r.Err == nil
+}
+
+// entry 转换为下发记录，供 verify 子命令对比
+func (r appResult) entry(at time.Time) applylog.Entry {
+	e := applylog.Entry{Zone: r.Zone, AppID: r.AppID, OK: r.ok(), Message: r.Message, Time: at}
+	if r.Status != 0 {
+		e.Status = strconv.Itoa(r.Status)
+	}
+	if !r.ok() {
+		e.Message = r.Err.Error()
+	}
+	return e
+}
+
+// apply 将指定应用下发到资源管理器；非 2xx 响应和 code 不为 0 的响应按失败处理并带上服务端的错误信息
+func apply(ctx context.Context, client *zonesync.Client, zone string, id int64) appResult {
+	resp, err := client.ApplyToResourceManager(ctx, zonesync.ApplyRequest{AppID: id})
+	result := appResult{Zone: zone, AppID: id, Err: err}
+	if resp != nil {
+		result.Status = resp.StatusCode
+		result.Message = resp.ServerMessage()
+	}
+	return result
+}
+
+// printResults 以表格形式输出每个应用的结果，返回失败数量
+func printResults(w io.Writer, results []appResult) (failed int) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ZONE\tAPP_ID\tSTATUS\tMESSAGE")
+	for _, r := range results {
+		status := "-"
+		if r.Status != 0 {
+			status = fmt.Sprint(r.Status)
+		}
+		msg := r.Message
+		if !r.ok() {
+			failed++
+			msg = "FAILED: " + r.Err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", r.Zone, r.AppID, status, msg)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "total=%d success=%d failed=%d\n", len(results), len(results)-failed, failed)
+	return failed
+}
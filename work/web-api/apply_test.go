@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"learn-go/work/zonesync"
+)
+
+func TestApplyHandlesStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "funnydb" || pass != "p" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"code":401,"message":"unauthorized"}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/zone-sync/apps/apply-to-resource-manager/12":
+			fmt.Fprint(w, `{"code":0,"message":"ok"}`)
+		case "/api/v1/zone-sync/apps/apply-to-resource-manager/13":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"code":500,"message":"resource manager unavailable"}`)
+		case "/api/v1/zone-sync/apps/apply-to-resource-manager/15":
+			fmt.Fprint(w, `{"code":5003,"message":"app is disabled"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	cred := Credentials{Username: "funnydb", Password: "p"}
+	tests := []struct {
+		id      int64
+		cred    Credentials
+		status  int
+		ok      bool
+		message string
+	}{
+		{12, cred, 200, true, "ok"},
+		{13, cred, 500, false, "resource manager unavailable"},
+		{14, cred, 404, false, "404 page not found"},
+		{15, cred, 200, false, "app is disabled"},
+		{12, Credentials{Username: "funnydb"}, 401, false, "unauthorized"},
+	}
+	for _, tt := range tests {
+		client, err := zonesync.NewClient(srv.URL, zonesync.WithHTTPClient(srv.Client()), zonesync.WithBasicAuth(tt.cred.Username, tt.cred.Password))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := apply(context.Background(), client, "cn", tt.id)
+		if r.Status != tt.status || r.ok() != tt.ok || r.Message != tt.message {
+			t.Errorf("apply(%d) = status %d ok %v message %q, want %d %v %q", tt.id, r.Status, r.ok(), r.Message, tt.status, tt.ok, tt.message)
+		}
+	}
+}
+
+func TestApplyTransportError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	client, err := zonesync.NewClient(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := apply(context.Background(), client, "cn", 12)
+	if r.ok() || r.Status != 0 {
+		t.Errorf("apply = %+v, want transport error", r)
+	}
+}
+
+func TestPrintResults(t *testing.T) {
+	var sb strings.Builder
+	failed := printResults(&sb, []appResult{
+		{Zone: "cn", AppID: 12, Status: 200, Message: "ok"},
+		{Zone: "cn", AppID: 13, Status: 500, Message: "boom", Err: fmt.Errorf("HTTP 500: boom")},
+	})
+	if failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+	for _, want := range []string{"cn    12      200     ok", "FAILED: HTTP 500: boom", "total=2 success=1 failed=1"} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, sb.String())
+		}
+	}
+}
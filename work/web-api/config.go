@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Auth 区域接口的认证信息，配置文件中只保存用户名，密码通过环境变量或凭据文件提供
+type Auth struct {
+	Username string `json:"username"`
+	Password string `json:"password,omitempty"`
+}
+
+// ZoneProfile 单个区域的配置：接口地址、认证信息以及默认处理的应用 ID
+type ZoneProfile struct {
+	BaseURL string  `json:"baseURL"`
+	Auth    Auth    `json:"auth"`
+	AppIDs  []int64 `json:"appIds"`
+}
+
+// Config 所有区域的配置，key 为区域名（cn、sg ...）
+type Config struct {
+	Zones map[string]ZoneProfile `json:"zones"`
+}
+
+// loadConfig 从 JSON 文件读取区域配置
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置失败: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("解析配置失败: %w", err)
+	}
+	for name, zone := range cfg.Zones {
+		if zone.BaseURL == "" {
+			return nil, fmt.Errorf("区域 %s 缺少 baseURL", name)
+		}
+		if zone.Auth.Password != "" {
+			return nil, fmt.Errorf("区域 %s 的密码不允许写在配置文件中", name)
+		}
+	}
+	return &cfg, nil
+}
+
+// selectZones 根据 --zone 参数选出需要处理的区域名，all 表示全部区域（按名称排序）
+func (c *Config) selectZones(zone string) ([]string, error) {
+	if zone == "all" {
+		names := make([]string, 0, len(c.Zones))
+		for name := range c.Zones {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+	if _, ok := c.Zones[zone]; !ok {
+		return nil, fmt.Errorf("未知区域: %s", zone)
+	}
+	return []string{zone}, nil
+}
+
+// maxAppIDs 一次选择的应用 ID 数量上限，避免写错的区间展开出海量 ID
+const maxAppIDs = 10000
+
+// parseAppIDs 解析应用 ID 选择表达式，支持单个 ID 和区间，例如 "12,13,80-90"，展开后最多 maxAppIDs 个
+func parseAppIDs(expr string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, err := strconv.ParseInt(strings.TrimSpace(lo), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("无效的应用 ID: %q", part)
+		}
+		end := start
+		if isRange {
+			end, err = strconv.ParseInt(strings.TrimSpace(hi), 10, 64)
+			if err != nil || end < start {
+				return nil, fmt.Errorf("无效的应用 ID 区间: %q", part)
+			}
+		}
+		// 先比较区间长度再展开，end 接近 MaxInt64 时 id++ 不会回绕
+		if end-start >= int64(maxAppIDs-len(ids)) {
+			return nil, fmt.Errorf("应用 ID 过多: %q 超出上限 %d 个", part, maxAppIDs)
+		}
+		for n := int64(0); n <= end-start; n++ {
+			ids = append(ids, start+n)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestParseAppIDs(t *testing.T) {
+	got, err := parseAppIDs(" 12, 13,80-83 ,")
+	if want := []int64{12, 13, 80, 81, 82, 83}; err != nil || !slices.Equal(got, want) {
+		t.Errorf("got %v, %v, want %v", got, err, want)
+	}
+
+	maxID := strconv.FormatInt(math.MaxInt64, 10)
+	if got, err := parseAppIDs(maxID); err != nil || !slices.Equal(got, []int64{math.MaxInt64}) {
+		t.Errorf("parseAppIDs(MaxInt64) = %v, %v", got, err)
+	}
+	for _, bad := range []string{"x", "5-3", "1-", "0-" + maxID, "0-10000", "1-5000,6000-11000"} {
+		if _, err := parseAppIDs(bad); err == nil {
+			t.Errorf("parseAppIDs(%q): want error", bad)
+		}
+	}
+	if got, err := parseAppIDs("1-9999,10000"); err != nil || len(got) != maxAppIDs {
+		t.Errorf("恰好 %d 个 ID 应当允许: %d, %v", maxAppIDs, len(got), err)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Credentials 区域接口的 Basic 认证凭据
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// credentialStore 凭据来源，按区域名查找凭据；找不到时返回 ok=false
+type credentialStore interface {
+	lookup(zone string) (cred Credentials, ok bool, err error)
+}
+
+// envStore 从环境变量读取凭据：ZONESYNC_<ZONE>_USERNAME / ZONESYNC_<ZONE>_PASSWORD
+type envStore struct{}
+
+func (envStore) lookup(zone string) (Credentials, bool, error) {
+	prefix := "ZONESYNC_" + strings.ToUpper(zone) + "_"
+	password, ok := os.LookupEnv(prefix + "PASSWORD")
+	if !ok {
+		return Credentials{}, false, nil
+	}
+	return Credentials{Username: os.Getenv(prefix + "USERNAME"), Password: password}, true, nil
+}
+
+// fileStore 从本地凭据文件读取，文件内容为 {"cn": {"username": "...", "password": "..."}}
+type fileStore struct {
+	path string
+}
+
+func (s fileStore) lookup(zone string) (Credentials, bool, error) {
+	data, err := readPrivateFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Credentials{}, false, nil
+	}
+	if err != nil {
+		return Credentials{}, false, err
+	}
+	return lookupZone(data, zone, s.path)
+}
+
+// lookupZone 从凭据 JSON 中取出指定区域的凭据
+func lookupZone(data []byte, zone, source string) (Credentials, bool, error) {
+	var all map[string]Credentials
+	if err := json.Unmarshal(data, &all); err != nil {
+		return Credentials{}, false, fmt.Errorf("解析凭据 %s 失败: %w", source, err)
+	}
+	cred, ok := all[zone]
+	return cred, ok, nil
+}
+
+// readPrivateFile 读取只允许当前用户访问的文件，组或其他用户可读写时拒绝读取
+func readPrivateFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("凭据文件 %s 权限过宽 (%v)，请执行 chmod 600", path, info.Mode().Perm())
+	}
+	return os.ReadFile(path)
+}
+
+// defaultConfigFile 返回用户配置目录下 zone-sync 的文件路径，获取失败时返回空字符串
+func defaultConfigFile(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "zone-sync", name)
+}
+
+// resolveCredentials 依次从各个来源查找区域凭据，配置中的用户名作为缺省值，短于 minSecretLen 的密码视为错误
+func resolveCredentials(stores []credentialStore, zone string, profile ZoneProfile) (Credentials, error) {
+	for _, store := range stores {
+		cred, ok, err := store.lookup(zone)
+		if err != nil {
+			return Credentials{}, err
+		}
+		if !ok {
+			continue
+		}
+		if cred.Username == "" {
+			cred.Username = profile.Auth.Username
+		}
+		if cred.Password == "" {
+			return Credentials{}, fmt.Errorf("区域 %s 的凭据缺少 password", zone)
+		}
+		if len(cred.Password) < minSecretLen {
+			return Credentials{}, fmt.Errorf("区域 %s 的密码过短，至少需要 %d 个字符", zone, minSecretLen)
+		}
+		return cred, nil
+	}
+	return Credentials{}, fmt.Errorf("未找到区域 %s 的凭据，请设置 ZONESYNC_%s_PASSWORD 或配置凭据文件", zone, strings.ToUpper(zone))
+}
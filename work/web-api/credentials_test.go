@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, name, content string, perm os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestResolveCredentialsOrder(t *testing.T) {
+	t.Setenv("ZONESYNC_CN_PASSWORD", "from-env")
+	file := writeFile(t, "credentials.json", `{"cn":{"password":"from-file"},"sg":{"username":"u","password":"from-file"}}`, 0o600)
+	stores := []credentialStore{envStore{}, fileStore{path: file}}
+	profile := ZoneProfile{Auth: Auth{Username: "funnydb"}}
+
+	cred, err := resolveCredentials(stores, "cn", profile)
+	if err != nil || cred != (Credentials{Username: "funnydb", Password: "from-env"}) {
+		t.Errorf("cn = %+v, %v", cred, err)
+	}
+	cred, err = resolveCredentials(stores, "sg", profile)
+	if err != nil || cred != (Credentials{Username: "u", Password: "from-file"}) {
+		t.Errorf("sg = %+v, %v", cred, err)
+	}
+	if _, err = resolveCredentials(stores, "us", profile); err == nil {
+		t.Error("us: want error for missing credentials")
+	}
+
+	t.Setenv("ZONESYNC_CN_PASSWORD", "short")
+	if _, err = resolveCredentials(stores, "cn", profile); err == nil || !strings.Contains(err.Error(), "过短") {
+		t.Errorf("short password: err = %v", err)
+	}
+}
+
+func TestFileStoreRejectsLoosePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows 不检查文件权限")
+	}
+	file := writeFile(t, "credentials.json", `{"cn":{"password":"p"}}`, 0o644)
+	if _, _, err := (fileStore{path: file}).lookup("cn"); err == nil || !strings.Contains(err.Error(), "权限过宽") {
+		t.Errorf("lookup = %v, want permission error", err)
+	}
+}
+
+func TestSecretStoreRoundTrip(t *testing.T) {
+	plain := writeFile(t, "plain.json", `{"cn":{"username":"funnydb","password":"s3cret"}}`, 0o600)
+	sealed := filepath.Join(t.TempDir(), "secrets.enc")
+	passphrase := func() (string, error) { return "correct horse", nil }
+	if err := sealFile(plain, sealed, passphrase); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(sealed)
+	if strings.Contains(string(data), "s3cret") {
+		t.Fatal("加密文件中出现了明文密码")
+	}
+
+	store := &secretStore{path: sealed, passphrase: passphrase}
+	cred, ok, err := store.lookup("cn")
+	if err != nil || !ok || cred.Password != "s3cret" {
+		t.Errorf("lookup = %+v, %v, %v", cred, ok, err)
+	}
+
+	wrong := &secretStore{path: sealed, passphrase: func() (string, error) { return "wrong", nil }}
+	if _, _, err := wrong.lookup("cn"); err == nil {
+		t.Error("wrong passphrase: want error")
+	}
+}
+
+// stubExitOnLeak 把 exitOnLeak 替换为计数，返回调用次数
+func stubExitOnLeak(t *testing.T) *int {
+	t.Helper()
+	var exits int
+	orig := exitOnLeak
+	exitOnLeak = func(error) { exits++ }
+	t.Cleanup(func() { exitOnLeak = orig })
+	return &exits
+}
+
+func TestGuardWriterRefusesSecrets(t *testing.T) {
+	exits := stubExitOnLeak(t)
+	var sb strings.Builder
+	g := &guardWriter{w: &sb}
+	g.protect(Credentials{Username: "funnydb", Password: "s3cret-pass"})
+
+	if _, err := g.Write([]byte("appId=12 ok\n")); err != nil {
+		t.Fatal(err)
+	}
+	header := "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte("funnydb:s3cret-pass"))
+	for _, line := range []string{"password=s3cret-pass", header} {
+		if _, err := g.Write([]byte(line)); !errors.Is(err, errSecretLeak) {
+			t.Errorf("Write(%q) = %v, want errSecretLeak", line, err)
+		}
+	}
+	if sb.String() != "appId=12 ok\n" {
+		t.Errorf("output = %q", sb.String())
+	}
+	if *exits != 2 {
+		t.Errorf("exitOnLeak called %d times, want 2", *exits)
+	}
+}
+
+func TestGuardWriterStopsLogOutput(t *testing.T) {
+	exits := stubExitOnLeak(t)
+	var sb strings.Builder
+	g := &guardWriter{w: &sb}
+	g.protect(Credentials{Username: "funnydb", Password: "s3cret-pass"})
+
+	// log 包忽略 Write 返回的错误，泄露时必须由 guardWriter 自己终止运行
+	logger := log.New(g, "", 0)
+	logger.Printf("request failed: password=%s", "s3cret-pass")
+	if *exits != 1 || sb.Len() != 0 {
+		t.Errorf("exits = %d, output = %q", *exits, sb.String())
+	}
+}
+
+func TestGuardWriterRefusesShortPasswords(t *testing.T) {
+	exits := stubExitOnLeak(t)
+	var sb strings.Builder
+	g := &guardWriter{w: &sb}
+	g.protect(Credentials{Username: "funnydb", Password: "200"})
+
+	// 即使密码很短也按明文拦截，resolveCredentials 保证实际使用的密码不会这么短
+	if _, err := g.Write([]byte("appId=12 status=200\n")); !errors.Is(err, errSecretLeak) || *exits != 1 {
+		t.Errorf("Write = %v, exits = %d", err, *exits)
+	}
+}
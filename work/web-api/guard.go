@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// errSecretLeak 输出内容中包含凭据时返回
+var errSecretLeak = errors.New("输出内容包含凭据，已拒绝输出")
+
+// minSecretLen 密码的最小长度。过短的密码容易与正常输出（如应用 ID、状态码）重合，无法按明文拦截，
+// resolveCredentials 直接拒绝这样的密码，所有下发使用的密码都受 guardWriter 保护
+const minSecretLen = 8
+
+// exitOnLeak 发现凭据时调用。log 包会忽略 Writer 返回的错误，只靠返回 errSecretLeak 无法终止运行，
+// 因此由 guardWriter 直接退出；测试中替换为记录调用
+var exitOnLeak = func(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+// guardWriter 在写出前检查内容，发现已知凭据（明文密码或 Basic 认证串）时拒绝写出并终止运行，
+// 通过 printf 和 log 输出的内容同样适用
+type guardWriter struct {
+	w       io.Writer
+	secrets [][]byte
+}
+
+// protect 登记需要保护的凭据
+func (g *guardWriter) protect(cred Credentials) {
+	if cred.Password == "" {
+		return
+	}
+	basic := base64.StdEncoding.EncodeToString([]byte(cred.Username + ":" + cred.Password))
+	g.secrets = append(g.secrets, []byte(basic), []byte(cred.Password))
+}
+
+func (g *guardWriter) Write(p []byte) (int, error) {
+	for _, s := range g.secrets {
+		if bytes.Contains(p, s) {
+			exitOnLeak(errSecretLeak)
+			return 0, errSecretLeak
+		}
+	}
+	return g.w.Write(p)
+}
+
+// printf 格式化输出一行，内容包含凭据时拒绝输出并终止运行
+func (g *guardWriter) printf(format string, a ...any) {
+	fmt.Fprintf(g, format, a...)
+}
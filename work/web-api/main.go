@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"learn-go/work/applylog"
+	"learn-go/work/zonesync"
+)
+
+// usage 命令用法
+const usage = `用法:
+  web-api [apply] [flags]   将应用下发到资源管理器（默认子命令）
+  web-api verify [flags]    对比 zone-sync 接口与 Resource gRPC 服务记录的下发结果
+
+执行 web-api <子命令> -h 查看参数说明`
+
+// options 各子命令共用的参数
+type options struct {
+	configPath      string
+	zone            string
+	apps            string
+	credentialsPath string
+	secretsPath     string
+	passphraseFile  string
+	timeout         time.Duration
+}
+
+// register 在 FlagSet 上注册共用参数
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.configPath, "config", "zones.json", "区域配置文件路径")
+	fs.StringVar(&o.zone, "zone", "cn", "要处理的区域: cn|sg|all")
+	fs.StringVar(&o.apps, "apps", "", "应用 ID 选择，例如 12,13,80-90；为空时使用区域配置中的默认列表")
+	fs.StringVar(&o.credentialsPath, "credentials", defaultConfigFile("credentials.json"), "明文凭据文件路径（权限须为 600）")
+	fs.StringVar(&o.secretsPath, "secrets", defaultConfigFile("secrets.enc"), "加密凭据文件路径")
+	fs.StringVar(&o.passphraseFile, "passphrase-file", "", "加密凭据的口令文件，未设置 ZONESYNC_PASSPHRASE 时使用")
+	fs.DurationVar(&o.timeout, "timeout", zonesync.DefaultTimeout, "单次请求超时时间")
+}
+
+// zoneTarget 一个待处理的区域：配置、接口客户端和应用列表
+type zoneTarget struct {
+	name    string
+	profile ZoneProfile
+	client  *zonesync.Client
+	appIDs  []int64
+}
+
+// session 解析完配置和凭据后的运行上下文
+type session struct {
+	targets []zoneTarget
+	out     *guardWriter
+}
+
+func main() {
+	args := os.Args[1:]
+	cmd := "apply"
+	if len(args) > 0 && (args[0] == "apply" || args[0] == "verify") {
+		cmd, args = args[0], args[1:]
+	} else if len(args) > 0 && args[0] == "help" {
+		fmt.Println(usage)
+		return
+	}
+
+	var err error
+	switch cmd {
+	case "apply":
+		err = runApply(args)
+	case "verify":
+		err = runVerify(args)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newSession 读取配置、解析应用列表，并为每个区域解析凭据、创建接口客户端
+func newSession(o *options) (*session, error) {
+	cfg, err := loadConfig(o.configPath)
+	if err != nil {
+		return nil, err
+	}
+	zones, err := cfg.selectZones(o.zone)
+	if err != nil {
+		return nil, err
+	}
+	var selected []int64
+	if o.apps != "" {
+		if selected, err = parseAppIDs(o.apps); err != nil {
+			return nil, err
+		}
+	}
+
+	// 凭据查找顺序：环境变量 > 明文凭据文件 > 加密凭据文件
+	stores := []credentialStore{
+		envStore{},
+		fileStore{path: o.credentialsPath},
+		&secretStore{path: o.secretsPath, passphrase: envPassphrase(o.passphraseFile)},
+	}
+	s := &session{out: &guardWriter{w: os.Stdout}}
+	for _, name := range zones {
+		profile := cfg.Zones[name]
+		cred, err := resolveCredentials(stores, name, profile)
+		if err != nil {
+			return nil, err
+		}
+		s.out.protect(cred)
+		client, err := zonesync.NewClient(profile.BaseURL,
+			zonesync.WithBasicAuth(cred.Username, cred.Password),
+			zonesync.WithTimeout(o.timeout),
+		)
+		if err != nil {
+			return nil, err
+		}
+		appIDs := profile.AppIDs
+		if selected != nil {
+			appIDs = selected
+		}
+		s.targets = append(s.targets, zoneTarget{name: name, profile: profile, client: client, appIDs: appIDs})
+	}
+	log.SetOutput(&guardWriter{w: os.Stderr, secrets: s.out.secrets})
+	return s, nil
+}
+
+// runApply 将选中区域的应用逐个下发到资源管理器，最后输出汇总表
+func runApply(args []string) error {
+	fs := flag.NewFlagSet("apply", flag.ExitOnError)
+	var o options
+	o.register(fs)
+	seal := fs.String("seal", "", "将指定的明文凭据文件加密写入 --secrets 后退出")
+	record := fs.String("record", "", "将每个应用的结果写入该文件，供 verify 子命令对比")
+	fs.Parse(args)
+
+	if *seal != "" {
+		if err := sealFile(*seal, o.secretsPath, envPassphrase(o.passphraseFile)); err != nil {
+			return err
+		}
+		log.Printf("已加密写入 %s", o.secretsPath)
+		return nil
+	}
+
+	s, err := newSession(&o)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	var results []appResult
+	for _, t := range s.targets {
+		s.out.printf("== zone=%s (%d apps)\n", t.name, len(t.appIDs))
+		for _, id := range t.appIDs {
+			// 单个应用失败不影响后续应用，最后统一汇总
+			r := apply(ctx, t.client, t.name, id)
+			if r.ok() {
+				s.out.printf("appId=%d status=%d\n", id, r.Status)
+			} else {
+				s.out.printf("appId=%d failed: %v\n", id, r.Err)
+			}
+			results = append(results, r)
+		}
+	}
+
+	if *record != "" {
+		now := time.Now()
+		entries := make([]applylog.Entry, 0, len(results))
+		for _, r := range results {
+			entries = append(entries, r.entry(now))
+		}
+		if err := applylog.Write(*record, entries); err != nil {
+			return err
+		}
+	}
+
+	var table bytes.Buffer
+	failed := printResults(&table, results)
+	s.out.printf("\n%s", table.String())
+	if failed > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
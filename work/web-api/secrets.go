@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// pbkdf2Iterations 由口令派生密钥时的迭代次数
+const pbkdf2Iterations = 600_000
+
+// sealedSecrets 加密凭据文件的格式，data 为 AES-256-GCM 加密后的凭据 JSON
+type sealedSecrets struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// secretStore 本地加密凭据文件，使用口令解锁，解密结果在进程内缓存
+type secretStore struct {
+	path       string
+	passphrase func() (string, error)
+
+	plain []byte
+}
+
+func (s *secretStore) lookup(zone string) (Credentials, bool, error) {
+	if s.plain == nil {
+		data, err := readPrivateFile(s.path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return Credentials{}, false, nil
+		}
+		if err != nil {
+			return Credentials{}, false, err
+		}
+		passphrase, err := s.passphrase()
+		if err != nil {
+			return Credentials{}, false, err
+		}
+		if s.plain, err = openSecrets(data, passphrase); err != nil {
+			return Credentials{}, false, fmt.Errorf("解锁 %s 失败: %w", s.path, err)
+		}
+	}
+	return lookupZone(s.plain, zone, s.path)
+}
+
+// envPassphrase 从 ZONESYNC_PASSPHRASE 环境变量或口令文件读取解锁口令
+func envPassphrase(file string) func() (string, error) {
+	return func() (string, error) {
+		if p, ok := os.LookupEnv("ZONESYNC_PASSPHRASE"); ok {
+			return p, nil
+		}
+		if file == "" {
+			return "", errors.New("需要口令解锁加密凭据，请设置 ZONESYNC_PASSPHRASE 或 --passphrase-file")
+		}
+		data, err := readPrivateFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+}
+
+// deriveKey 使用 PBKDF2-SHA256 由口令派生 AES-256 密钥
+func deriveKey(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealSecrets 使用口令加密凭据 JSON
+func sealSecrets(plain []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("口令不能为空")
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return json.Marshal(sealedSecrets{Salt: salt, Nonce: nonce, Data: aead.Seal(nil, nonce, plain, nil)})
+}
+
+// openSecrets 使用口令解密凭据文件，口令错误或文件被篡改时返回错误
+func openSecrets(data []byte, passphrase string) ([]byte, error) {
+	var sealed sealedSecrets
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		return nil, err
+	}
+	aead, err := deriveKey(passphrase, sealed.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed.Nonce) != aead.NonceSize() {
+		return nil, errors.New("加密凭据格式错误")
+	}
+	plain, err := aead.Open(nil, sealed.Nonce, sealed.Data, nil)
+	if err != nil {
+		return nil, errors.New("口令错误或文件已损坏")
+	}
+	return plain, nil
+}
+
+// sealFile 将明文凭据文件加密写入 dst，用于首次生成加密凭据
+func sealFile(src, dst string, passphrase func() (string, error)) error {
+	plain, err := readPrivateFile(src)
+	if err != nil {
+		return err
+	}
+	if _, _, err := lookupZone(plain, "", src); err != nil {
+		return err
+	}
+	p, err := passphrase()
+	if err != nil {
+		return err
+	}
+	sealed, err := sealSecrets(plain, p)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, sealed, 0o600)
+}
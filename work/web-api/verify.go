@@ -0,0 +1,149 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"google.golang.org/grpc/codes"
+	"learn-go/work/applylog"
+)
+
+// verifyState 两条下发路径结果的对比结论
+type verifyState string
+
+const (
+	stateConsistent   verifyState = "OK"
+	stateMismatch     verifyState = "MISMATCH"
+	stateInconclusive verifyState = "UNKNOWN"
+)
+
+// verifyResult 单个应用在 zone-sync 接口和 Resource gRPC 服务上记录的结果，
+// 某条路径没有该应用的记录时对应字段为 nil
+type verifyResult struct {
+	Zone   string
+	AppID  int64
+	HTTP   *applylog.Entry
+	GRPC   *applylog.Entry
+	State  verifyState
+	Reason string
+}
+
+// verifyApp 对比同一个应用在两条路径上记录的结果，只读取记录，不调用任何下发接口：
+// HTTP 成功但 gRPC 返回 NotFound 说明资源不存在；HTTP 失败但 gRPC 成功说明资源实际存在。
+func verifyApp(zone string, id int64, httpEntry, grpcEntry *applylog.Entry) verifyResult {
+	r := verifyResult{Zone: zone, AppID: id, HTTP: httpEntry, GRPC: grpcEntry}
+	switch {
+	case httpEntry == nil:
+		r.State, r.Reason = stateInconclusive, "HTTP 记录中没有该应用"
+	case grpcEntry == nil:
+		r.State, r.Reason = stateInconclusive, "gRPC 记录中没有该应用"
+	case !grpcEntry.OK && grpcEntry.Status != codes.NotFound.String():
+		r.State, r.Reason = stateInconclusive, "gRPC 调用失败: "+grpcEntry.Message
+	case httpEntry.OK && !grpcEntry.OK:
+		r.State, r.Reason = stateMismatch, "HTTP 成功但资源不存在"
+	case !httpEntry.OK && grpcEntry.OK:
+		r.State, r.Reason = stateMismatch, "HTTP 失败但资源存在: "+httpEntry.Message
+	default:
+		r.State = stateConsistent
+		if !httpEntry.OK {
+			r.Reason = "两边均失败"
+		}
+	}
+	return r
+}
+
+// recordKey 区域加应用 ID，唯一确定一条记录
+type recordKey struct {
+	zone string
+	id   int64
+}
+
+// compareRecords 按区域和应用 ID 配对两份记录并逐个对比，zone 不为 all 时只对比该区域。
+// 同一个应用有多条记录时以最后一条为准，结果按区域、应用 ID 排序
+func compareRecords(httpEntries, grpcEntries []applylog.Entry, zone string) []verifyResult {
+	index := func(entries []applylog.Entry) map[recordKey]*applylog.Entry {
+		m := make(map[recordKey]*applylog.Entry)
+		for i := range entries {
+			if e := &entries[i]; zone == "all" || e.Zone == zone {
+				m[recordKey{e.Zone, e.AppID}] = e
+			}
+		}
+		return m
+	}
+	httpIndex, grpcIndex := index(httpEntries), index(grpcEntries)
+
+	var results []verifyResult
+	for k, e := range httpIndex {
+		results = append(results, verifyApp(k.zone, k.id, e, grpcIndex[k]))
+	}
+	for k, e := range grpcIndex {
+		if httpIndex[k] == nil {
+			results = append(results, verifyApp(k.zone, k.id, nil, e))
+		}
+	}
+	slices.SortFunc(results, func(a, b verifyResult) int {
+		return cmp.Or(cmp.Compare(a.Zone, b.Zone), cmp.Compare(a.AppID, b.AppID))
+	})
+	return results
+}
+
+// printVerifyResults 以表格形式输出对比结果，返回不一致和无法判断的数量
+func printVerifyResults(w io.Writer, results []verifyResult) (mismatched, unknown int) {
+	status := func(e *applylog.Entry) string {
+		if e == nil || e.Status == "" {
+			return "-"
+		}
+		return e.Status
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ZONE\tAPP_ID\tHTTP\tGRPC\tRESULT\tREASON")
+	for _, r := range results {
+		switch r.State {
+		case stateMismatch:
+			mismatched++
+		case stateInconclusive:
+			unknown++
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\n", r.Zone, r.AppID, status(r.HTTP), status(r.GRPC), r.State, r.Reason)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "total=%d mismatched=%d unknown=%d\n", len(results), mismatched, unknown)
+	return mismatched, unknown
+}
+
+// runVerify 读取 web-api apply -record 和 grpc 客户端 -record 写入的两份下发记录，报告两边不一致的应用。
+// 对比只读取记录文件，不会再次下发
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	httpRecord := fs.String("http-record", "", "web-api apply -record 写入的 zone-sync 接口下发记录")
+	grpcRecord := fs.String("grpc-record", "", "grpc 客户端 -record 写入的 Resource.Apply 下发记录")
+	zone := fs.String("zone", "all", "要对比的区域: cn|sg|all")
+	fs.Parse(args)
+
+	if *httpRecord == "" || *grpcRecord == "" {
+		return errors.New("verify 需要同时指定 -http-record 和 -grpc-record")
+	}
+	httpEntries, err := applylog.Read(*httpRecord)
+	if err != nil {
+		return err
+	}
+	grpcEntries, err := applylog.Read(*grpcRecord)
+	if err != nil {
+		return err
+	}
+
+	results := compareRecords(httpEntries, grpcEntries, *zone)
+	if len(results) == 0 {
+		return fmt.Errorf("记录中没有区域 %s 的应用", *zone)
+	}
+	if mismatched, unknown := printVerifyResults(os.Stdout, results); mismatched+unknown > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
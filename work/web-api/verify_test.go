@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"learn-go/work/applylog"
+	resource "learn-go/work/grpc/api"
+	"learn-go/work/grpc/record"
+	"learn-go/work/zonesync"
+)
+
+// newZoneSyncClient 启动 zone-sync 接口替身，ok 中的应用返回 200，其余返回 500
+func newZoneSyncClient(t *testing.T, ok map[int64]bool) *zonesync.Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/zone-sync/apps/apply-to-resource-manager/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var id int64
+		fmt.Sscan(r.PathValue("id"), &id)
+		if !ok[id] {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"code":500,"message":"apply failed"}`)
+			return
+		}
+		fmt.Fprint(w, `{"code":0,"message":"ok"}`)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	client, err := zonesync.NewClient(srv.URL, zonesync.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// fakeResourceServer Resource 服务替身，codes 中的应用返回对应的状态码，其余应用视为资源不存在
+type fakeResourceServer struct {
+	resource.UnimplementedResourceServer
+	codes map[uint64]codes.Code
+}
+
+func (s *fakeResourceServer) Apply(_ context.Context, req *resource.ApplyRequest) (*emptypb.Empty, error) {
+	code, ok := s.codes[req.GetAppId()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "app %d not found", req.GetAppId())
+	}
+	if code != codes.OK {
+		return nil, status.Error(code, "resource manager is down")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// newResourceClient 在内存连接上启动 Resource 服务替身，返回连接到它的客户端
+func newResourceClient(t *testing.T, codes map[uint64]codes.Code) resource.ResourceClient {
+	t.Helper()
+	s := grpc.NewServer()
+	resource.RegisterResourceServer(s, &fakeResourceServer{codes: codes})
+	lis := bufconn.Listen(1 << 20)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return resource.NewResourceClient(conn)
+}
+
+func TestVerifyRecords(t *testing.T) {
+	// HTTP 记录来自 apply 对替身接口的真实调用
+	client := newZoneSyncClient(t, map[int64]bool{12: true, 13: true, 16: true})
+	now := time.Now()
+	var httpEntries []applylog.Entry
+	for _, id := range []int64{12, 13, 14, 15, 16, 17} {
+		httpEntries = append(httpEntries, apply(context.Background(), client, "cn", id).entry(now))
+	}
+	// gRPC 记录来自 grpc 客户端使用的 record.Apply 对替身服务的真实调用，13、15 不存在，16 服务不可用
+	grpcClient := newResourceClient(t, map[uint64]codes.Code{12: codes.OK, 14: codes.OK, 16: codes.Unavailable, 18: codes.OK, 4: codes.OK})
+	var grpcEntries []applylog.Entry
+	for _, app := range []struct {
+		zone string
+		id   uint64
+	}{{"cn", 12}, {"cn", 13}, {"cn", 14}, {"cn", 15}, {"cn", 16}, {"cn", 18}, {"sg", 4}} {
+		entry, _ := record.Apply(context.Background(), grpcClient, app.zone, app.id)
+		grpcEntries = append(grpcEntries, entry)
+	}
+	if e := grpcEntries[1]; e.OK || e.Status != codes.NotFound.String() || e.Message != "app 13 not found" {
+		t.Errorf("NotFound 记录 = %+v", e)
+	}
+
+	// 经过记录文件往返，与 verify 子命令读取的数据一致
+	dir := t.TempDir()
+	httpPath, grpcPath := filepath.Join(dir, "http.json"), filepath.Join(dir, "grpc.json")
+	if err := applylog.Write(httpPath, httpEntries); err != nil {
+		t.Fatal(err)
+	}
+	if err := applylog.Write(grpcPath, grpcEntries); err != nil {
+		t.Fatal(err)
+	}
+	httpEntries, _ = applylog.Read(httpPath)
+	grpcEntries, _ = applylog.Read(grpcPath)
+
+	results := compareRecords(httpEntries, grpcEntries, "cn")
+	tests := []struct {
+		id     int64
+		state  verifyState
+		reason string
+	}{
+		{12, stateConsistent, ""},
+		{13, stateMismatch, "HTTP 成功但资源不存在"},
+		{14, stateMismatch, "HTTP 失败但资源存在"},
+		{15, stateConsistent, "两边均失败"},
+		{16, stateInconclusive, "gRPC 调用失败: resource manager is down"},
+		{17, stateInconclusive, "gRPC 记录中没有该应用"},
+		{18, stateInconclusive, "HTTP 记录中没有该应用"},
+	}
+	if len(results) != len(tests) {
+		t.Fatalf("got %d results, want %d: %+v", len(results), len(tests), results)
+	}
+	for i, tt := range tests {
+		r := results[i]
+		if r.AppID != tt.id || r.State != tt.state || !strings.HasPrefix(r.Reason, tt.reason) {
+			t.Errorf("result %d = %d %s %q, want %d %s %q", i, r.AppID, r.State, r.Reason, tt.id, tt.state, tt.reason)
+		}
+	}
+
+	var sb strings.Builder
+	mismatched, unknown := printVerifyResults(&sb, results)
+	if mismatched != 2 || unknown != 3 {
+		t.Errorf("mismatched=%d unknown=%d, want 2 3\n%s", mismatched, unknown, sb.String())
+	}
+	if !strings.Contains(sb.String(), "total=7 mismatched=2 unknown=3") {
+		t.Errorf("summary missing:\n%s", sb.String())
+	}
+
+	if all := compareRecords(httpEntries, grpcEntries, "all"); len(all) != 8 || all[7].Zone != "sg" {
+		t.Errorf("zone=all: %+v", all)
+	}
+}
@@ -0,0 +1,34 @@
+package zonesync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ApplyToResourceManager 将应用下发到资源管理器。
+// 请求失败时仍会返回 ApplyResponse，其中带有状态码和服务端消息，便于调用方汇总结果。
+// 2xx 响应中 code 不为 0 同样视为失败，返回 *APIError。
+func (c *Client) ApplyToResourceManager(ctx context.Context, req ApplyRequest) (*ApplyResponse, error) {
+	resp := &ApplyResponse{AppID: req.AppID}
+	status, data, err := c.send(ctx, http.MethodGet, req.path(), nil)
+	resp.StatusCode = status
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			resp.Code = apiErr.Code
+			resp.Message = apiErr.Message
+		}
+		return resp, err
+	}
+	// 成功响应不一定是 JSON，解析失败时保留原始内容作为消息
+	if err := json.Unmarshal(data, &resp.Response); err != nil {
+		resp.Response = Response{Message: truncate(strings.TrimSpace(string(data)))}
+	}
+	if resp.Code != 0 {
+		return resp, &APIError{StatusCode: status, Code: resp.Code, Message: resp.ServerMessage()}
+	}
+	return resp, nil
+}
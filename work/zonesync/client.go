@@ -0,0 +1,182 @@
+// Package zonesync 封装 zone-sync REST 接口的客户端。
+//
+// 下发接口由 ApplyToResourceManager 单独封装；其他返回统一 Response 结构的接口通过 Call 调用，
+// 同样得到类型化的响应和错误，返回结构不同的接口通过 Do 解析到调用方自己的类型。
+package zonesync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout 单次请求的默认超时时间
+	DefaultTimeout = 30 * time.Second
+	// DefaultUserAgent 默认的 User-Agent
+	DefaultUserAgent = "zonesync-go/1.0"
+
+	// maxBodySize 读取响应体的上限，避免异常响应占用过多内存
+	maxBodySize = 1 << 20
+)
+
+// Client zone-sync 接口客户端，可在多个 goroutine 中复用
+type Client struct {
+	baseURL    *url.URL
+	username   string
+	password   string
+	httpClient *http.Client
+	userAgent  string
+	// timeout 非 0 时覆盖 httpClient 的超时时间，在全部 Option 执行完之后生效
+	timeout time.Duration
+}
+
+// Option 客户端配置项
+type Option func(*Client)
+
+// WithBasicAuth 使用 Basic 认证
+func WithBasicAuth(username, password string) Option {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithHTTPClient 使用自定义的 http.Client，例如测试中的 httptest 客户端；hc 为 nil 时 NewClient 返回错误
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTimeout 设置单次请求的超时时间，与 WithHTTPClient 同时使用时不受两者顺序影响，
+// 也不会修改调用方传入的 http.Client
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithUserAgent 设置请求的 User-Agent
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// NewClient 创建客户端，baseURL 形如 https://api-funnydb.zh-cn.xmfunny.com
+func NewClient(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("zonesync: 无效的 baseURL %q: %w", baseURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("zonesync: 无效的 baseURL %q", baseURL)
+	}
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		userAgent:  DefaultUserAgent,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.httpClient == nil {
+		return nil, errors.New("zonesync: http.Client 不能为 nil")
+	}
+	if c.timeout != 0 {
+		hc := *c.httpClient
+		hc.Timeout = c.timeout
+		c.httpClient = &hc
+	}
+	return c, nil
+}
+
+// BaseURL 返回客户端访问的接口地址
+func (c *Client) BaseURL() string {
+	return c.baseURL.String()
+}
+
+// Do 发送请求并将 2xx 响应解析到 out；out 为 nil 时丢弃响应体。
+// 尚未单独封装的 zone-sync 接口可以直接通过 Do 调用。
+func (c *Client) Do(ctx context.Context, method, path string, body io.Reader, out any) error {
+	status, data, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return &DecodeError{StatusCode: status, Body: truncate(string(data)), Err: err}
+	}
+	return nil
+}
+
+// Call 调用返回统一 Response 结构的 zone-sync 接口，in 不为 nil 时编码为 JSON 请求体。
+// 非 2xx 响应和 code 不为 0 的 2xx 响应都返回 *APIError，此时 Response 中仍带有服务端消息
+func (c *Client) Call(ctx context.Context, method, path string, in any) (*Response, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, fmt.Errorf("zonesync: 编码请求失败: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	status, data, err := c.send(ctx, method, path, body)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			return &Response{Code: apiErr.Code, Message: apiErr.Message}, err
+		}
+		return nil, err
+	}
+	var resp Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, &DecodeError{StatusCode: status, Body: truncate(string(data)), Err: err}
+	}
+	if resp.Code != 0 {
+		return &resp, &APIError{StatusCode: status, Code: resp.Code, Message: resp.ServerMessage()}
+	}
+	return &resp, nil
+}
+
+// send 发送请求并读取响应体，非 2xx 响应返回 *APIError
+func (c *Client) send(ctx context.Context, method, path string, body io.Reader) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL.JoinPath(path).String(), body)
+	if err != nil {
+		return 0, nil, err
+	}
+	if c.username != "" || c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, &TransportError{Method: method, Path: path, Err: err}
+	}
+	// 读取完毕后立即关闭，保证连接可被复用
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxBodySize))
+	if err != nil {
+		return res.StatusCode, nil, &TransportError{Method: method, Path: path, Err: err}
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, data, newAPIError(res.StatusCode, data)
+	}
+	return res.StatusCode, data, nil
+}
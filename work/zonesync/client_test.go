@@ -0,0 +1,196 @@
+package zonesync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// newTestServer 模拟 zone-sync 接口：12 成功，13 服务端错误，14 返回非 JSON，15 超时，16 返回 200 但 code 不为 0，其余 404
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/zone-sync/apps/apply-to-resource-manager/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "funnydb" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"code":401,"message":"invalid credentials"}`)
+			return
+		}
+		if ua := r.Header.Get("User-Agent"); ua != "zonesync-test" {
+			t.Errorf("User-Agent = %q", ua)
+		}
+		switch r.PathValue("id") {
+		case "12":
+			fmt.Fprint(w, `{"code":0,"message":"ok","data":{"appId":12}}`)
+		case "13":
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, `{"code":5002,"error":"resource manager unavailable"}`)
+		case "14":
+			fmt.Fprint(w, "done")
+		case "16":
+			fmt.Fprint(w, `{"code":5003,"message":"app is disabled"}`)
+		case "15":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	// 模拟返回统一 Response 结构的其他接口：name 为空时 code 不为 0
+	mux.HandleFunc("POST /api/v1/zone-sync/echo", func(w http.ResponseWriter, r *http.Request) {
+		var in struct{ Name string }
+		json.NewDecoder(r.Body).Decode(&in)
+		if in.Name == "" {
+			fmt.Fprint(w, `{"code":4001,"message":"name is required"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"code":0,"message":"ok","data":{"name":%q}}`, in.Name)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(t *testing.T, srv *httptest.Server, opts ...Option) *Client {
+	t.Helper()
+	opts = append([]Option{WithHTTPClient(srv.Client()), WithBasicAuth("funnydb", "secret"), WithUserAgent("zonesync-test")}, opts...)
+	c, err := NewClient(srv.URL+"/", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestApplyToResourceManager(t *testing.T) {
+	c := newTestClient(t, newTestServer(t))
+	ctx := context.Background()
+
+	resp, err := c.ApplyToResourceManager(ctx, ApplyRequest{AppID: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 200 || resp.Code != 0 || resp.Message != "ok" || string(resp.Data) != `{"appId":12}` {
+		t.Errorf("resp = %+v", resp)
+	}
+
+	resp, err = c.ApplyToResourceManager(ctx, ApplyRequest{AppID: 14})
+	if err != nil || resp.Message != "done" {
+		t.Errorf("non-JSON body: resp = %+v, err = %v", resp, err)
+	}
+}
+
+func TestApplyToResourceManagerErrors(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t, srv)
+	ctx := context.Background()
+
+	resp, err := c.ApplyToResourceManager(ctx, ApplyRequest{AppID: 13})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 502 || apiErr.Code != 5002 || apiErr.Message != "resource manager unavailable" {
+		t.Errorf("err = %#v", err)
+	}
+	if resp.StatusCode != 502 || resp.Message != "resource manager unavailable" {
+		t.Errorf("resp = %+v", resp)
+	}
+
+	resp, err = c.ApplyToResourceManager(ctx, ApplyRequest{AppID: 16})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 200 || apiErr.Code != 5003 || apiErr.Message != "app is disabled" {
+		t.Errorf("err = %#v", err)
+	}
+	if resp.StatusCode != 200 || resp.Code != 5003 {
+		t.Errorf("resp = %+v", resp)
+	}
+
+	if _, err := c.ApplyToResourceManager(ctx, ApplyRequest{AppID: 99}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+
+	anon, _ := NewClient(srv.URL, WithHTTPClient(srv.Client()))
+	if _, err := anon.ApplyToResourceManager(ctx, ApplyRequest{AppID: 12}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("err = %v, want ErrUnauthorized", err)
+	}
+
+	slow := newTestClient(t, srv, WithTimeout(50*time.Millisecond))
+	var transportErr *TransportError
+	if _, err := slow.ApplyToResourceManager(ctx, ApplyRequest{AppID: 15}); !errors.As(err, &transportErr) {
+		t.Errorf("err = %v, want *TransportError", err)
+	}
+}
+
+func TestDoDecodeError(t *testing.T) {
+	c := newTestClient(t, newTestServer(t))
+	var out struct{ Code int }
+	err := c.Do(context.Background(), http.MethodGet, ApplyRequest{AppID: 14}.path(), nil, &out)
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Body != "done" {
+		t.Errorf("err = %v, want *DecodeError", err)
+	}
+}
+
+func TestNewClientRejectsInvalidURL(t *testing.T) {
+	for _, u := range []string{"", "api-funnydb.sg.xmfunny.com", "ftp://example.com", "http://[::1"} {
+		if _, err := NewClient(u); err == nil {
+			t.Errorf("NewClient(%q): want error", u)
+		}
+	}
+}
+
+func TestCall(t *testing.T) {
+	c := newTestClient(t, newTestServer(t))
+	ctx := context.Background()
+
+	resp, err := c.Call(ctx, http.MethodPost, "/api/v1/zone-sync/echo", map[string]string{"name": "cn"})
+	if err != nil || resp.Message != "ok" || string(resp.Data) != `{"name":"cn"}` {
+		t.Errorf("resp = %+v, err = %v", resp, err)
+	}
+
+	// 2xx 但 code 不为 0 按失败处理
+	resp, err = c.Call(ctx, http.MethodPost, "/api/v1/zone-sync/echo", struct{}{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 200 || apiErr.Code != 4001 || apiErr.Message != "name is required" {
+		t.Errorf("err = %#v", err)
+	}
+	if resp == nil || resp.Code != 4001 {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestWithTimeoutOrder(t *testing.T) {
+	srv := newTestServer(t)
+	hc := srv.Client()
+	for _, opts := range [][]Option{
+		{WithTimeout(50 * time.Millisecond), WithHTTPClient(hc)},
+		{WithHTTPClient(hc), WithTimeout(50 * time.Millisecond)},
+	} {
+		c, err := NewClient(srv.URL, append(opts, WithBasicAuth("funnydb", "secret"), WithUserAgent("zonesync-test"))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.httpClient.Timeout != 50*time.Millisecond {
+			t.Errorf("Timeout = %v, want 50ms", c.httpClient.Timeout)
+		}
+	}
+	if hc.Timeout != 0 {
+		t.Errorf("caller's http.Client was modified: Timeout = %v", hc.Timeout)
+	}
+
+	if _, err := NewClient(srv.URL, WithHTTPClient(nil), WithTimeout(time.Second)); err == nil {
+		t.Error("WithHTTPClient(nil): want error")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	s := truncate(strings.Repeat("资", 100))
+	if !utf8.ValidString(s) || !strings.HasSuffix(s, "...") || len(s) > maxMessageLen+len("...") {
+		t.Errorf("truncate = %q", s)
+	}
+	if s := truncate("short"); s != "short" {
+		t.Errorf("truncate = %q", s)
+	}
+}
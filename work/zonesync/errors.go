@@ -0,0 +1,103 @@
+package zonesync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrUnauthorized 认证失败（401/403）
+	ErrUnauthorized = errors.New("zonesync: unauthorized")
+	// ErrNotFound 资源不存在（404）
+	ErrNotFound = errors.New("zonesync: not found")
+)
+
+// APIError 服务端返回的失败响应：非 2xx 状态码，或 2xx 但 JSON 中的 code 不为 0
+type APIError struct {
+	StatusCode int
+	Code       int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("zonesync: HTTP %d (code %d): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("zonesync: HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// Is 支持 errors.Is(err, ErrUnauthorized) 等按状态码分类的判断
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// newAPIError 根据响应体构造 APIError，响应体不是 JSON 时使用原始内容作为消息
+func newAPIError(status int, body []byte) *APIError {
+	e := &APIError{StatusCode: status}
+	var resp Response
+	if err := json.Unmarshal(body, &resp); err == nil && resp.ServerMessage() != "" {
+		e.Code = resp.Code
+		e.Message = resp.ServerMessage()
+		return e
+	}
+	e.Message = truncate(strings.TrimSpace(string(body)))
+	if e.Message == "" {
+		e.Message = http.StatusText(status)
+	}
+	return e
+}
+
+// TransportError 请求未能拿到完整响应（连接失败、超时等）
+type TransportError struct {
+	Method string
+	Path   string
+	Err    error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("zonesync: %s %s: %v", e.Method, e.Path, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError 2xx 响应无法解析为预期的 JSON
+type DecodeError struct {
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("zonesync: 解析响应失败 (HTTP %d): %v", e.StatusCode, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// maxMessageLen 错误消息中保留的响应内容的最大字节数
+const maxMessageLen = 200
+
+// truncate 截断过长的响应内容，截断位置向前退到字符边界，不会切开多字节的 UTF-8 字符
+func truncate(s string) string {
+	if len(s) <= maxMessageLen {
+		return s
+	}
+	n := maxMessageLen
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,39 @@
+package zonesync
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Response zone-sync 接口的通用返回结构
+type Response struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Error   string          `json:"error,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// ServerMessage 返回服务端消息，优先使用 message 字段
+func (r *Response) ServerMessage() string {
+	if r.Message != "" {
+		return r.Message
+	}
+	return r.Error
+}
+
+// ApplyRequest 将应用下发到资源管理器的请求
+type ApplyRequest struct {
+	AppID int64
+}
+
+// ApplyResponse 下发接口的返回结果
+type ApplyResponse struct {
+	AppID      int64
+	StatusCode int
+	Response
+}
+
+// path 下发接口路径
+func (r ApplyRequest) path() string {
+	return fmt.Sprintf("/api/v1/zone-sync/apps/apply-to-resource-manager/%d", r.AppID)
+}